@@ -2,17 +2,21 @@ package app
 
 import (
 	"gator/internal/config"
-	"gator/internal/database"
+	"gator/internal/migrate"
+	"gator/internal/storage"
 )
 
 type AppState struct {
 	AppConfig *config.Config
-	DB        *database.Queries
+	DB        storage.Store
+	// Migrator applies the schema for whichever backend DB talks to.
+	Migrator *migrate.Migrator
 }
 
-func NewAppState(config *config.Config, dbQueries *database.Queries) AppState {
+func NewAppState(config *config.Config, store storage.Store, migrator *migrate.Migrator) AppState {
 	return AppState{
 		AppConfig: config,
-		DB:        dbQueries,
+		DB:        store,
+		Migrator:  migrator,
 	}
 }
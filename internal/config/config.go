@@ -2,14 +2,32 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"time"
 )
 
 type Config struct {
-	DbUrl           string `json:"db_url"`
-	CurrentUserName string `json:"current_user_name"`
+	DbUrl           string      `json:"db_url"`
+	CurrentUserName string      `json:"current_user_name"`
+	Fetch           FetchConfig `json:"fetch"`
 }
 
+// FetchConfig controls how politely the scraper treats each host and how much
+// it is willing to download. All fields are optional; zero values fall back to
+// the defaults below and, for MaxBodyBytes, to utils.DefaultMaxBodyBytes.
+type FetchConfig struct {
+	PerHostDelay          string `json:"per_host_delay,omitempty"`
+	PerHostMaxConcurrency int    `json:"per_host_max_concurrency,omitempty"`
+	RespectRobotsTxt      bool   `json:"respect_robots_txt,omitempty"`
+	MaxBodyBytes          int64  `json:"max_body_bytes,omitempty"`
+}
+
+const (
+	defaultPerHostDelay          = time.Second
+	defaultPerHostMaxConcurrency = 1
+)
+
 const configFileName = "/.gatorconfig.json"
 
 func Read() (Config, error) {
@@ -48,3 +66,24 @@ func (c *Config) SetUser(username string) error {
 
 	return nil
 }
+
+func (f FetchConfig) HostDelay() (time.Duration, error) {
+	if f.PerHostDelay == "" {
+		return defaultPerHostDelay, nil
+	}
+	delay, err := time.ParseDuration(f.PerHostDelay)
+	if err != nil {
+		return 0, fmt.Errorf("invalid per_host_delay %q: %v", f.PerHostDelay, err)
+	}
+	if delay < 0 {
+		return 0, fmt.Errorf("per_host_delay must not be negative, got %s", delay)
+	}
+	return delay, nil
+}
+
+func (f FetchConfig) HostMaxConcurrency() int {
+	if f.PerHostMaxConcurrency < 1 {
+		return defaultPerHostMaxConcurrency
+	}
+	return f.PerHostMaxConcurrency
+}
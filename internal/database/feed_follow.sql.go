@@ -15,12 +15,12 @@ import (
 
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH new_follow AS (
-    INSERT INTO feed_follow (id, user_id, feed_id)
-    VALUES ($1, $2, $3)
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    INSERT INTO feed_follow (id, user_id, feed_id, folder_id)
+    VALUES ($1, $2, $3, $4)
+    RETURNING id, created_at, updated_at, user_id, feed_id, folder_id, title
 )
 SELECT 
-    new_follow.id, new_follow.created_at, new_follow.updated_at, new_follow.user_id, new_follow.feed_id,
+    new_follow.id, new_follow.created_at, new_follow.updated_at, new_follow.user_id, new_follow.feed_id, new_follow.folder_id, new_follow.title,
     users.name as user_name,
     feeds.name as feed_name
 FROM new_follow
@@ -29,9 +29,10 @@ JOIN feeds ON feeds.id = new_follow.feed_id
 `
 
 type CreateFeedFollowParams struct {
-	ID     uuid.UUID
-	UserID uuid.NullUUID
-	FeedID uuid.NullUUID
+	ID       uuid.UUID
+	UserID   uuid.NullUUID
+	FeedID   uuid.NullUUID
+	FolderID uuid.NullUUID
 }
 
 type CreateFeedFollowRow struct {
@@ -40,12 +41,19 @@ type CreateFeedFollowRow struct {
 	UpdatedAt time.Time
 	UserID    uuid.NullUUID
 	FeedID    uuid.NullUUID
+	FolderID  uuid.NullUUID
+	Title     sql.NullString
 	UserName  sql.NullString
 	FeedName  string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
-	row := q.db.QueryRowContext(ctx, createFeedFollow, arg.ID, arg.UserID, arg.FeedID)
+	row := q.db.QueryRowContext(ctx, createFeedFollow,
+		arg.ID,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+	)
 	var i CreateFeedFollowRow
 	err := row.Scan(
 		&i.ID,
@@ -53,6 +61,8 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.FolderID,
+		&i.Title,
 		&i.UserName,
 		&i.FeedName,
 	)
@@ -73,8 +83,36 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 	return err
 }
 
+const ensureFeedFollow = `-- name: EnsureFeedFollow :execrows
+INSERT INTO feed_follow (id, user_id, feed_id, folder_id, title)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, feed_id) DO NOTHING
+`
+
+type EnsureFeedFollowParams struct {
+	ID       uuid.UUID
+	UserID   uuid.NullUUID
+	FeedID   uuid.NullUUID
+	FolderID uuid.NullUUID
+	Title    sql.NullString
+}
+
+func (q *Queries) EnsureFeedFollow(ctx context.Context, arg EnsureFeedFollowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, ensureFeedFollow,
+		arg.ID,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		arg.Title,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
-SELECT id, created_at, updated_at, user_id, feed_id FROM feed_follow WHERE user_id = $1
+SELECT id, created_at, updated_at, user_id, feed_id, folder_id, title FROM feed_follow WHERE user_id = $1
 `
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUID) ([]FeedFollow, error) {
@@ -92,6 +130,82 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUI
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.FolderID,
+			&i.Title,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowedFeeds = `-- name: GetFollowedFeeds :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.etag, feeds.last_modified, feeds.last_error, feeds.consecutive_failures, feeds.next_fetch_at, feeds.disabled_at, feeds.claimed_by, feeds.lease_expires_at, feeds.site_url, feeds.description, coalesce(feed_follow.title, feeds.name) AS display_name, folders.name AS folder_name FROM feeds
+JOIN feed_follow ON feed_follow.feed_id = feeds.id
+LEFT JOIN folders ON folders.id = feed_follow.folder_id
+WHERE feed_follow.user_id = $1
+ORDER BY display_name
+`
+
+type GetFollowedFeedsRow struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	LastError           sql.NullString
+	ConsecutiveFailures int32
+	NextFetchAt         sql.NullTime
+	DisabledAt          sql.NullTime
+	ClaimedBy           sql.NullString
+	LeaseExpiresAt      sql.NullTime
+	SiteUrl             sql.NullString
+	Description         sql.NullString
+	DisplayName         string
+	FolderName          sql.NullString
+}
+
+// Each feed comes with the title the user gave it, or its own name.
+func (q *Queries) GetFollowedFeeds(ctx context.Context, userID uuid.NullUUID) ([]GetFollowedFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedFeeds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowedFeedsRow
+	for rows.Next() {
+		var i GetFollowedFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.NextFetchAt,
+			&i.DisabledAt,
+			&i.ClaimedBy,
+			&i.LeaseExpiresAt,
+			&i.SiteUrl,
+			&i.Description,
+			&i.DisplayName,
+			&i.FolderName,
 		); err != nil {
 			return nil, err
 		}
@@ -105,3 +219,41 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUI
 	}
 	return items, nil
 }
+
+const setFeedFollowFolder = `-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follow SET folder_id = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowFolderParams struct {
+	UserID   uuid.NullUUID
+	FeedID   uuid.NullUUID
+	FolderID uuid.NullUUID
+}
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg SetFeedFollowFolderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowFolder, arg.UserID, arg.FeedID, arg.FolderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setFeedFollowTitle = `-- name: SetFeedFollowTitle :execrows
+UPDATE feed_follow SET title = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowTitleParams struct {
+	UserID uuid.NullUUID
+	FeedID uuid.NullUUID
+	Title  sql.NullString
+}
+
+func (q *Queries) SetFeedFollowTitle(ctx context.Context, arg SetFeedFollowTitleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowTitle, arg.UserID, arg.FeedID, arg.Title)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
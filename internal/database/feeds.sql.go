@@ -7,31 +7,105 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET claimed_by = $1::text,
+    lease_expires_at = NOW() + make_interval(secs => $2::float8),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL
+    AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    AND (lease_expires_at IS NULL OR lease_expires_at <= NOW())
+    ORDER BY last_fetched_at ASC NULLS FIRST
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, last_error, consecutive_failures, next_fetch_at, disabled_at, claimed_by, lease_expires_at, site_url, description
+`
+
+type ClaimFeedsToFetchParams struct {
+	ClaimedBy    string
+	LeaseSeconds float64
+	MaxFeeds     int32
+}
+
+// Leases the stalest due feeds to one scraper instance. SKIP LOCKED lets
+// concurrent instances claim disjoint sets; an expired lease is up for grabs
+// again, so feeds held by a crashed instance are picked up by the others.
+// last_fetched_at is left to MarkFeedFetched, so a feed handed back unfetched
+// keeps its place in the queue.
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, arg ClaimFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimFeedsToFetch, arg.ClaimedBy, arg.LeaseSeconds, arg.MaxFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.NextFetchAt,
+			&i.DisabledAt,
+			&i.ClaimedBy,
+			&i.LeaseExpiresAt,
+			&i.SiteUrl,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, site_url, description)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8
 )
-RETURNING id, created_at, updated_at, name, url, user_id
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, last_error, consecutive_failures, next_fetch_at, disabled_at, claimed_by, lease_expires_at, site_url, description
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.UUID
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string
+	Url         string
+	UserID      uuid.UUID
+	SiteUrl     sql.NullString
+	Description sql.NullString
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -42,6 +116,8 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.SiteUrl,
+		arg.Description,
 	)
 	var i Feed
 	err := row.Scan(
@@ -51,12 +127,70 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Name,
 		&i.Url,
 		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.NextFetchAt,
+		&i.DisabledAt,
+		&i.ClaimedBy,
+		&i.LeaseExpiresAt,
+		&i.SiteUrl,
+		&i.Description,
 	)
 	return i, err
 }
 
+const getBrokenFeeds = `-- name: GetBrokenFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, last_error, consecutive_failures, next_fetch_at, disabled_at, claimed_by, lease_expires_at, site_url, description FROM feeds
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY consecutive_failures DESC, name
+`
+
+func (q *Queries) GetBrokenFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getBrokenFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.NextFetchAt,
+			&i.DisabledAt,
+			&i.ClaimedBy,
+			&i.LeaseExpiresAt,
+			&i.SiteUrl,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeed = `-- name: GetFeed :one
-SELECT id, created_at, updated_at, name, url, user_id FROM feeds WHERE id = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, last_error, consecutive_failures, next_fetch_at, disabled_at, claimed_by, lease_expires_at, site_url, description FROM feeds WHERE id = $1
 `
 
 func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -69,12 +203,23 @@ func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
 		&i.Name,
 		&i.Url,
 		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.NextFetchAt,
+		&i.DisabledAt,
+		&i.ClaimedBy,
+		&i.LeaseExpiresAt,
+		&i.SiteUrl,
+		&i.Description,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, last_error, consecutive_failures, next_fetch_at, disabled_at, claimed_by, lease_expires_at, site_url, description FROM feeds WHERE url = $1
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -87,6 +232,17 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Name,
 		&i.Url,
 		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.NextFetchAt,
+		&i.DisabledAt,
+		&i.ClaimedBy,
+		&i.LeaseExpiresAt,
+		&i.SiteUrl,
+		&i.Description,
 	)
 	return i, err
 }
@@ -103,25 +259,37 @@ func (q *Queries) GetFeedNameById(ctx context.Context, id uuid.UUID) (string, er
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT name, url, user_id FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, last_error, consecutive_failures, next_fetch_at, disabled_at, claimed_by, lease_expires_at, site_url, description FROM feeds
 `
 
-type GetFeedsRow struct {
-	Name   string
-	Url    string
-	UserID uuid.UUID
-}
-
-func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
+func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 	rows, err := q.db.QueryContext(ctx, getFeeds)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetFeedsRow
+	var items []Feed
 	for rows.Next() {
-		var i GetFeedsRow
-		if err := rows.Scan(&i.Name, &i.Url, &i.UserID); err != nil {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.NextFetchAt,
+			&i.DisabledAt,
+			&i.ClaimedBy,
+			&i.LeaseExpiresAt,
+			&i.SiteUrl,
+			&i.Description,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -134,3 +302,88 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	}
 	return items, nil
 }
+
+const markFeedFetched = `-- name: MarkFeedFetched :exec
+UPDATE feeds SET last_fetched_at = NOW(), updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+	return err
+}
+
+const recordFeedFetchFailure = `-- name: RecordFeedFetchFailure :exec
+UPDATE feeds
+SET last_error = $1,
+    consecutive_failures = consecutive_failures + 1,
+    next_fetch_at = NOW() + make_interval(secs => $2::float8),
+    disabled_at = CASE WHEN $3::boolean THEN NOW() END,
+    updated_at = NOW()
+WHERE id = $4
+`
+
+type RecordFeedFetchFailureParams struct {
+	LastError      sql.NullString
+	BackoffSeconds float64
+	Disable        bool
+	ID             uuid.UUID
+}
+
+// Both timestamps come from the database clock, the one ClaimFeedsToFetch
+// compares them against.
+func (q *Queries) RecordFeedFetchFailure(ctx context.Context, arg RecordFeedFetchFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedFetchFailure,
+		arg.LastError,
+		arg.BackoffSeconds,
+		arg.Disable,
+		arg.ID,
+	)
+	return err
+}
+
+const releaseFeedLease = `-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET claimed_by = NULL,
+    lease_expires_at = NULL
+WHERE id = $1 AND claimed_by = $2::text
+`
+
+type ReleaseFeedLeaseParams struct {
+	ID        uuid.UUID
+	ClaimedBy string
+}
+
+func (q *Queries) ReleaseFeedLease(ctx context.Context, arg ReleaseFeedLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedLease, arg.ID, arg.ClaimedBy)
+	return err
+}
+
+const resetFeedFetchErrors = `-- name: ResetFeedFetchErrors :exec
+UPDATE feeds
+SET last_error = NULL,
+    consecutive_failures = 0,
+    next_fetch_at = NULL,
+    disabled_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) ResetFeedFetchErrors(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, resetFeedFetchErrors, id)
+	return err
+}
+
+const setFeedCacheValidators = `-- name: SetFeedCacheValidators :exec
+UPDATE feeds SET etag = $2, last_modified = $3, updated_at = NOW() WHERE id = $1
+`
+
+type SetFeedCacheValidatorsParams struct {
+	ID           uuid.UUID
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) SetFeedCacheValidators(ctx context.Context, arg SetFeedCacheValidatorsParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedCacheValidators, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
@@ -3,10 +3,13 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"gator/internal/app"
 	"gator/internal/database"
 	"gator/internal/middleware"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,21 +23,30 @@ func RegisterFeedFollowHandlers(c *app.Commands) {
 	c.Register("follow", middlewareLoggedInWrapper(handleFollow))
 	c.Register("following", middlewareLoggedInWrapper(handleFollowing))
 	c.Register("unfollow", middlewareLoggedInWrapper(handleUnfollow))
+	c.Register("rename-feed", middlewareLoggedInWrapper(handleRenameFeed))
 }
 
 func middlewareLoggedInWrapper(handler func(*app.AppState, app.Command, database.User) error) func(*app.AppState, app.Command) error {
 	return middleware.MiddlewareLoggedIn(handler)
 }
 
+// handleFollow follows a feed, filing it under --folder when given. Following
+// a feed again with --folder moves it to that folder.
 func handleFollow(s *app.AppState, cmd app.Command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	folderName := fs.String("folder", "", "folder to file the feed under, created if missing")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if len(cmd.Args) < 1 {
+	if len(args) < 1 {
 		println(ErrorArgsNotFound)
 		return nil
 	}
 
-	feedUrl := cmd.Args[0]
+	feedUrl := args[0]
 	feed, err := s.DB.GetFeedByURL(ctx, feedUrl)
 	if err != nil {
 		return err
@@ -47,41 +59,77 @@ func handleFollow(s *app.AppState, cmd app.Command, user database.User) error {
 	if err != nil {
 		return err
 	}
-	_, err = s.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
-		ID:     uuid.New(),
-		UserID: uuid.NullUUID{UUID: userId, Valid: true},
-		FeedID: uuid.NullUUID{UUID: feedId, Valid: true},
-	})
-	if err != nil {
-		return err
+	if *folderName == "" {
+		_, err = s.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:     uuid.New(),
+			UserID: uuid.NullUUID{UUID: userId, Valid: true},
+			FeedID: uuid.NullUUID{UUID: feedId, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		return nil
 	}
 
-	return nil
-
+	return s.DB.InTx(ctx, func(qtx database.Querier) error {
+		folder, err := ensureFolder(ctx, qtx, user, *folderName)
+		if err != nil {
+			return err
+		}
+		folderId := uuid.NullUUID{UUID: folder.ID, Valid: true}
+		moved, err := qtx.SetFeedFollowFolder(ctx, database.SetFeedFollowFolderParams{
+			UserID:   uuid.NullUUID{UUID: userId, Valid: true},
+			FeedID:   uuid.NullUUID{UUID: feedId, Valid: true},
+			FolderID: folderId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move feed: %v", err)
+		}
+		if moved > 0 {
+			fmt.Printf("Moved %s to folder %s\n", feed.Name, folder.Name)
+			return nil
+		}
+		_, err = qtx.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:       uuid.New(),
+			UserID:   uuid.NullUUID{UUID: userId, Valid: true},
+			FeedID:   uuid.NullUUID{UUID: feedId, Valid: true},
+			FolderID: folderId,
+		})
+		return err
+	})
 }
 
+// handleFollowing lists the user's feeds, unfiled ones first and then those in
+// each folder.
 func handleFollowing(s *app.AppState, cmd app.Command, user database.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	userId, err := s.DB.GetUserIDByName(ctx, sql.NullString{
-		String: s.AppConfig.CurrentUserName,
-		Valid:  true,
-	})
+	feeds, err := s.DB.GetFollowedFeeds(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
 	if err != nil {
 		return err
 	}
-	follows, err := s.DB.GetFeedFollowsForUser(ctx, uuid.NullUUID{UUID: userId, Valid: true})
-	if err != nil {
-		return err
-	}
-	for _, follow := range follows {
-		feedId := follow.FeedID.UUID
-		feedName, err := s.DB.GetFeedNameById(ctx, feedId)
-		if err != nil {
-			return err
+
+	var folders []string
+	byFolder := map[string][]string{}
+	for _, feed := range feeds {
+		folder := feed.FolderName.String
+		if folder != "" {
+			if _, ok := byFolder[folder]; !ok {
+				folders = append(folders, folder)
+			}
 		}
-		fmt.Printf("-- %s\n", feedName)
+		byFolder[folder] = append(byFolder[folder], feed.DisplayName)
+	}
+	sort.Strings(folders)
 
+	for _, name := range byFolder[""] {
+		fmt.Printf("-- %s\n", name)
+	}
+	for _, folder := range folders {
+		fmt.Printf("%s/\n", folder)
+		for _, name := range byFolder[folder] {
+			fmt.Printf("  -- %s\n", name)
+		}
 	}
 	return nil
 
@@ -120,3 +168,49 @@ func handleUnfollow(s *app.AppState, cmd app.Command, user database.User) error
 	return nil
 
 }
+
+// handleRenameFeed sets the title the user sees for a feed they follow in
+// place of its name; --reset goes back to the feed's own name.
+func handleRenameFeed(s *app.AppState, cmd app.Command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	reset := fs.Bool("reset", false, "show the feed's own name again")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 || (len(args) < 2 && !*reset) {
+		fmt.Printf("Usage: %s <url> <title> | %s --reset <url>\n", cmd.Name, cmd.Name)
+		return nil
+	}
+	title := strings.TrimSpace(strings.Join(args[1:], " "))
+	if !*reset && title == "" {
+		return fmt.Errorf("title must not be empty, use --reset to clear it")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	feed, err := s.DB.GetFeedByURL(ctx, args[0])
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no feed with url %s", args[0])
+	} else if err != nil {
+		return fmt.Errorf("failed to look up feed: %v", err)
+	}
+	n, err := s.DB.SetFeedFollowTitle(ctx, database.SetFeedFollowTitleParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		FeedID: uuid.NullUUID{UUID: feed.ID, Valid: true},
+		Title:  sql.NullString{String: title, Valid: !*reset},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename feed: %v", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("you don't follow %s", args[0])
+	}
+	if *reset {
+		fmt.Printf("%s is shown as %s again\n", args[0], feed.Name)
+	} else {
+		fmt.Printf("%s is now shown as %s\n", args[0], title)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"gator/internal/app"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFollow(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+		mustRun(t, s, "register", "bob")
+
+		mustRun(t, s, "follow", srv.URL+"/feed.xml")
+		bob := getUser(t, s, "bob")
+		if got := followedURLs(t, s, bob); !reflect.DeepEqual(got, []string{srv.URL + "/feed.xml"}) {
+			t.Errorf("bob follows %v", got)
+		}
+		if err := run(t, s, "follow", srv.URL+"/feed.xml"); err == nil {
+			t.Error("following a feed twice succeeded")
+		}
+		if err := run(t, s, "follow", srv.URL+"/unknown.xml"); err == nil {
+			t.Error("following an unknown feed succeeded")
+		}
+
+		mustRun(t, s, "unfollow", srv.URL+"/feed.xml")
+		if got := followedURLs(t, s, bob); len(got) != 0 {
+			t.Errorf("bob still follows %v", got)
+		}
+		if got := followedURLs(t, s, getUser(t, s, "alice")); len(got) != 1 {
+			t.Errorf("unfollowing for bob changed alice's follows to %v", got)
+		}
+	})
+}
+
+func TestFollowFolder(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+		mustRun(t, s, "register", "bob")
+
+		mustRun(t, s, "follow", srv.URL+"/feed.xml", "--folder", "Go")
+		// Following again with --folder moves the feed.
+		mustRun(t, s, "follow", "--folder", "Reading", srv.URL+"/feed.xml")
+
+		bob := getUser(t, s, "bob")
+		folders, err := s.DB.GetFoldersForUser(context.Background(), bob.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var counts []string
+		for _, folder := range folders {
+			counts = append(counts, fmt.Sprintf("%s:%d", folder.Name, folder.FeedCount))
+		}
+		if want := []string{"Go:0", "Reading:1"}; !reflect.DeepEqual(counts, want) {
+			t.Errorf("folders = %v, want %v", counts, want)
+		}
+
+		folders, err = s.DB.GetFoldersForUser(context.Background(), getUser(t, s, "alice").ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(folders) != 0 {
+			t.Errorf("alice has folders %v", folders)
+		}
+	})
+}
+
+func TestFollowNotLoggedIn(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+		mustRun(t, s, "register", "bob")
+		s.AppConfig.CurrentUserName = ""
+
+		mustRun(t, s, "follow", srv.URL+"/feed.xml")
+		if got := followedURLs(t, s, getUser(t, s, "bob")); len(got) != 0 {
+			t.Errorf("bob follows %v without being logged in", got)
+		}
+	})
+}
+
+func TestRenameFeed(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+		mustRun(t, s, "addfeed", srv.URL+"/go.xml")
+		mustRun(t, s, "follow", "--folder", "Go", srv.URL+"/go.xml")
+		addPost(t, s, srv.URL+"/go.xml", "G", "2024-01-01")
+
+		if out := output(t, s, "rename-feed", srv.URL+"/go.xml", "The", "Go", "Blog"); !strings.Contains(out, "is now shown as The Go Blog") {
+			t.Errorf("rename-feed printed %q", out)
+		}
+		if out, want := output(t, s, "following"), "-- Blog\nGo/\n  -- The Go Blog\n"; out != want {
+			t.Errorf("following printed %q, want %q", out, want)
+		}
+		if out := output(t, s, "browse"); !strings.Contains(out, "Feed: The Go Blog\n") {
+			t.Errorf("browse printed %q", out)
+		}
+		path := filepath.Join(t.TempDir(), "subs.opml")
+		mustRun(t, s, "export-opml", "-o", path)
+		var titles []string
+		for _, sub := range readOPML(t, path) {
+			titles = append(titles, sub.Title)
+		}
+		if want := []string{"Blog", "The Go Blog"}; !reflect.DeepEqual(titles, want) {
+			t.Errorf("export titles = %v, want %v", titles, want)
+		}
+
+		// The title is alice's own; the feed keeps its name for others.
+		mustRun(t, s, "register", "bob")
+		mustRun(t, s, "follow", srv.URL+"/go.xml")
+		if out := output(t, s, "following"); out != "-- Blog: Go\n" {
+			t.Errorf("bob's following printed %q", out)
+		}
+		if err := run(t, s, "rename-feed", srv.URL+"/feed.xml", "Mine"); err == nil {
+			t.Error("renaming a feed bob does not follow succeeded")
+		}
+		if err := run(t, s, "rename-feed", srv.URL+"/unknown.xml", "Mine"); err == nil {
+			t.Error("renaming an unknown feed succeeded")
+		}
+		if err := run(t, s, "rename-feed", srv.URL+"/go.xml", " "); err == nil {
+			t.Error("renaming to a blank title succeeded")
+		}
+
+		mustRun(t, s, "login", "alice")
+		if out := output(t, s, "rename-feed", "--reset", srv.URL+"/go.xml"); !strings.Contains(out, "is shown as Blog: Go again") {
+			t.Errorf("rename-feed --reset printed %q", out)
+		}
+		if out, want := output(t, s, "following"), "-- Blog\nGo/\n  -- Blog: Go\n"; out != want {
+			t.Errorf("following after --reset printed %q, want %q", out, want)
+		}
+		if out := output(t, s, "browse"); !strings.Contains(out, "Feed: Blog: Go\n") {
+			t.Errorf("browse after --reset printed %q", out)
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"flag"
+	"io"
+)
+
+// parseFlags parses flags that may appear anywhere among a command's
+// arguments, which the flag package alone does not allow, and returns the
+// remaining positional arguments in order. A "--" ends flag parsing, so the
+// arguments after it are positional even when they start with "-".
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		args = rest
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gator/internal/app"
+	"gator/internal/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func RegisterFolderHandlers(c *app.Commands) {
+	c.Register("folder", middlewareLoggedInWrapper(handleFolder))
+}
+
+// handleFolder manages the folders a user files subscriptions under.
+func handleFolder(s *app.AppState, cmd app.Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		fmt.Printf("Usage: %s create|rename|delete|list\n", cmd.Name)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	args := cmd.Args[1:]
+	switch cmd.Args[0] {
+	case "create":
+		if len(args) < 1 {
+			fmt.Printf("Usage: %s create <name>\n", cmd.Name)
+			return nil
+		}
+		_, err := s.DB.GetFolderByName(ctx, database.GetFolderByNameParams{UserID: user.ID, Name: args[0]})
+		if err == nil {
+			return fmt.Errorf("folder %q already exists", args[0])
+		} else if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to look up folder: %v", err)
+		}
+		if _, err := createFolder(ctx, s.DB, user, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Created folder %s\n", args[0])
+	case "rename":
+		if len(args) < 2 {
+			fmt.Printf("Usage: %s rename <name> <new name>\n", cmd.Name)
+			return nil
+		}
+		_, err := s.DB.GetFolderByName(ctx, database.GetFolderByNameParams{UserID: user.ID, Name: args[1]})
+		if err == nil {
+			return fmt.Errorf("folder %q already exists", args[1])
+		} else if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to look up folder: %v", err)
+		}
+		n, err := s.DB.RenameFolder(ctx, database.RenameFolderParams{
+			NewName: args[1],
+			UserID:  user.ID,
+			Name:    args[0],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rename folder: %v", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("no folder %q", args[0])
+		}
+		fmt.Printf("Renamed folder %s to %s\n", args[0], args[1])
+	case "delete":
+		if len(args) < 1 {
+			fmt.Printf("Usage: %s delete <name>\n", cmd.Name)
+			return nil
+		}
+		n, err := s.DB.DeleteFolder(ctx, database.DeleteFolderParams{UserID: user.ID, Name: args[0]})
+		if err != nil {
+			return fmt.Errorf("failed to delete folder: %v", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("no folder %q", args[0])
+		}
+		fmt.Printf("Deleted folder %s, its feeds are still followed\n", args[0])
+	case "list":
+		folders, err := s.DB.GetFoldersForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get folders: %v", err)
+		}
+		if len(folders) == 0 {
+			fmt.Println("No folders")
+			return nil
+		}
+		for _, folder := range folders {
+			fmt.Printf("%s (%d feeds)\n", folder.Name, folder.FeedCount)
+		}
+	default:
+		return fmt.Errorf("unknown folder subcommand %q", cmd.Args[0])
+	}
+	return nil
+}
+
+// getFolder looks up one of the user's folders by name.
+func getFolder(ctx context.Context, q database.Querier, user database.User, name string) (database.Folder, error) {
+	folder, err := q.GetFolderByName(ctx, database.GetFolderByNameParams{UserID: user.ID, Name: name})
+	if err == sql.ErrNoRows {
+		return folder, fmt.Errorf("no folder %q", name)
+	} else if err != nil {
+		return folder, fmt.Errorf("failed to look up folder: %v", err)
+	}
+	return folder, nil
+}
+
+// ensureFolder returns the user's folder with the given name, creating it if
+// needed.
+func ensureFolder(ctx context.Context, q database.Querier, user database.User, name string) (database.Folder, error) {
+	folder, err := q.GetFolderByName(ctx, database.GetFolderByNameParams{UserID: user.ID, Name: name})
+	if err == sql.ErrNoRows {
+		return createFolder(ctx, q, user, name)
+	} else if err != nil {
+		return folder, fmt.Errorf("failed to look up folder: %v", err)
+	}
+	return folder, nil
+}
+
+func createFolder(ctx context.Context, q database.Querier, user database.User, name string) (database.Folder, error) {
+	folder, err := q.CreateFolder(ctx, database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+	})
+	if err != nil {
+		return folder, fmt.Errorf("failed to create folder %s: %v", name, err)
+	}
+	return folder, nil
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"gator/internal/app"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFolder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		if out := output(t, s, "folder", "list"); out != "No folders\n" {
+			t.Errorf("list printed %q", out)
+		}
+
+		mustRun(t, s, "folder", "create", "Go")
+		mustRun(t, s, "folder", "create", "News")
+		if err := run(t, s, "folder", "create", "Go"); err == nil {
+			t.Error("creating a folder twice succeeded")
+		}
+		if out := output(t, s, "folder", "list"); out != "Go (0 feeds)\nNews (0 feeds)\n" {
+			t.Errorf("list printed %q", out)
+		}
+
+		if err := run(t, s, "folder", "rename", "Go", "News"); err == nil {
+			t.Error("renaming onto an existing folder succeeded")
+		}
+		if err := run(t, s, "folder", "rename", "Nope", "Other"); err == nil {
+			t.Error("renaming an unknown folder succeeded")
+		}
+		mustRun(t, s, "folder", "rename", "Go", "Golang")
+		if out := output(t, s, "folder", "list"); out != "Golang (0 feeds)\nNews (0 feeds)\n" {
+			t.Errorf("list after rename printed %q", out)
+		}
+
+		mustRun(t, s, "folder", "delete", "News")
+		if err := run(t, s, "folder", "delete", "News"); err == nil {
+			t.Error("deleting a folder twice succeeded")
+		}
+		if err := run(t, s, "folder", "move", "Golang"); err == nil {
+			t.Error("an unknown subcommand succeeded")
+		}
+
+		// Folders are per user.
+		mustRun(t, s, "register", "bob")
+		if out := output(t, s, "folder", "list"); out != "No folders\n" {
+			t.Errorf("bob's list printed %q", out)
+		}
+		mustRun(t, s, "folder", "create", "Golang")
+	})
+}
+
+func TestFolderFeeds(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+		mustRun(t, s, "addfeed", srv.URL+"/go.xml")
+		mustRun(t, s, "addfeed", srv.URL+"/rdf.xml")
+		mustRun(t, s, "follow", "--folder", "Go", srv.URL+"/go.xml")
+		mustRun(t, s, "follow", "--folder", "Reading", srv.URL+"/rdf.xml")
+		addPost(t, s, srv.URL+"/feed.xml", "A", "2024-01-01")
+		addPost(t, s, srv.URL+"/go.xml", "G", "2024-01-02")
+		addPost(t, s, srv.URL+"/rdf.xml", "R", "2024-01-03")
+
+		if out := output(t, s, "folder", "list"); out != "Go (1 feeds)\nReading (1 feeds)\n" {
+			t.Errorf("list printed %q", out)
+		}
+		// Unfiled feeds come first, then each folder in order.
+		if out, want := output(t, s, "following"), "-- Blog\nGo/\n  -- Blog: Go\nReading/\n  -- Journal\n"; out != want {
+			t.Errorf("following printed %q, want %q", out, want)
+		}
+
+		if got := postTitles(output(t, s, "browse", "--folder", "Go", "10")); !reflect.DeepEqual(got, []string{"G"}) {
+			t.Errorf("browse --folder Go = %v", got)
+		}
+		mustRun(t, s, "read", srv.URL+"/go.xml#G")
+		if out := output(t, s, "browse", "--folder", "Go"); !strings.Contains(out, "No unread posts") {
+			t.Errorf("browse --folder Go after reading printed %q", out)
+		}
+		if got := postTitles(output(t, s, "browse", "--folder", "Go", "--all")); !reflect.DeepEqual(got, []string{"G"}) {
+			t.Errorf("browse --folder Go --all = %v", got)
+		}
+		if err := run(t, s, "browse", "--folder", "Nope"); err == nil {
+			t.Error("browse accepted an unknown folder")
+		}
+
+		// Deleting a folder keeps its feeds followed, unfiled.
+		if out := output(t, s, "folder", "delete", "Go"); !strings.Contains(out, "its feeds are still followed") {
+			t.Errorf("delete printed %q", out)
+		}
+		if got := followedURLs(t, s, getUser(t, s, "alice")); len(got) != 3 {
+			t.Errorf("alice follows %v after deleting the folder", got)
+		}
+		if out, want := output(t, s, "following"), "-- Blog\n-- Blog: Go\nReading/\n  -- Journal\n"; out != want {
+			t.Errorf("following after delete printed %q, want %q", out, want)
+		}
+		if got := postTitles(output(t, s, "browse", "--all", "10")); !reflect.DeepEqual(got, []string{"R", "G", "A"}) {
+			t.Errorf("browse after delete = %v", got)
+		}
+	})
+}
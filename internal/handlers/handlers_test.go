@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gator/internal/app"
+	"gator/internal/config"
+	"gator/internal/database"
+	"gator/internal/migrate"
+	"gator/internal/storage"
+	"gator/internal/storage/storetest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// forEachStore runs test against the in-memory fake and against SQLite, which
+// runs the generated queries, so the two are held to the same behaviour.
+func forEachStore(t *testing.T, test func(t *testing.T, s *app.AppState)) {
+	t.Run("fake", func(t *testing.T) {
+		test(t, newTestState(t, storetest.New(), nil))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		store, migrator, err := storage.Open("sqlite://" + t.TempDir() + "/gator.db")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		test(t, newTestState(t, store, migrator))
+	})
+}
+
+// newTestState points HOME at a temporary directory, as register and login
+// save the config file there.
+func newTestState(t *testing.T, store storage.Store, migrator *migrate.Migrator) *app.AppState {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	s := app.NewAppState(&config.Config{}, store, migrator)
+	return &s
+}
+
+func newTestCommands() *app.Commands {
+	c := app.NewCommands()
+	RegisterUserHandlers(c)
+	RegisterRSSHandlers(c)
+	RegisterFeedFollowHandlers(c)
+	RegisterFolderHandlers(c)
+	RegisterPostHandlers(c)
+	RegisterOPMLHandlers(c)
+	return c
+}
+
+func run(t *testing.T, s *app.AppState, name string, args ...string) error {
+	t.Helper()
+	return newTestCommands().Run(s, app.Command{Name: name, Args: args})
+}
+
+func mustRun(t *testing.T, s *app.AppState, name string, args ...string) {
+	t.Helper()
+	if err := run(t, s, name, args...); err != nil {
+		t.Fatalf("%s %v: %v", name, args, err)
+	}
+}
+
+// output runs a command that must succeed and returns what it printed.
+func output(t *testing.T, s *app.AppState, name string, args ...string) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	err = run(t, s, name, args...)
+	os.Stdout = stdout
+	w.Close()
+	out := <-done
+	if err != nil {
+		t.Fatalf("%s %v: %v", name, args, err)
+	}
+	return out
+}
+
+const testFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<title>%s</title><link>https://blog.example</link><description>Posts about Go</description>
+<item><title>First</title><link>https://blog.example/first</link><pubDate>Mon, 02 Jan 2006 15:04:05 GMT</pubDate></item>
+</channel></rss>`
+
+const testRDFFeed = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel rdf:about="https://journal.example/"><title>Journal</title><link>https://journal.example/</link></channel>
+<item rdf:about="https://journal.example/1"><title>Paper</title><link>https://journal.example/1</link><dc:date>2006-01-02T15:04:05Z</dc:date><dc:creator>Ada Lovelace</dc:creator></item>
+</rdf:RDF>`
+
+// newFeedServer serves a blog whose home page links its feed at /feed.xml.
+// /go.xml is a second feed, /old.xml redirects to /feed.xml and /two links
+// both feeds. /rdf.xml is an RSS 1.0 feed.
+func newFeedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><head><link rel="alternate" type="application/rss+xml" href="/old.xml"></head></html>`)
+		case "/two":
+			fmt.Fprint(w, `<html><head>
+<link rel="alternate" type="application/rss+xml" title="All" href="/feed.xml">
+<link rel="alternate" type="application/rss+xml" title="Go" href="/go.xml">
+</head></html>`)
+		case "/old.xml":
+			http.Redirect(w, r, "/feed.xml", http.StatusMovedPermanently)
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, testFeed, "Blog")
+		case "/go.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, testFeed, "Blog: Go")
+		case "/rdf.xml":
+			w.Header().Set("Content-Type", "application/rdf+xml")
+			fmt.Fprint(w, testRDFFeed)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getUser(t *testing.T, s *app.AppState, name string) database.User {
+	t.Helper()
+	user, err := s.DB.GetUser(context.Background(), sql.NullString{String: name, Valid: true})
+	if err != nil {
+		t.Fatalf("user %s: %v", name, err)
+	}
+	return user
+}
+
+func getFeed(t *testing.T, s *app.AppState, url string) database.Feed {
+	t.Helper()
+	feed, err := s.DB.GetFeedByURL(context.Background(), url)
+	if err != nil {
+		t.Fatalf("feed %s: %v", url, err)
+	}
+	return feed
+}
+
+// followedURLs lists the URLs of the feeds user follows.
+func followedURLs(t *testing.T, s *app.AppState, user database.User) []string {
+	t.Helper()
+	feeds, err := s.DB.GetFollowedFeeds(context.Background(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, feed := range feeds {
+		urls = append(urls, feed.Url)
+	}
+	return urls
+}
+
+// addPost stores a post on the feed at feedURL, published on the given
+// YYYY-MM-DD date. Its link is the feed URL with the title as fragment.
+func addPost(t *testing.T, s *app.AppState, feedURL, title, published string) database.CreatePostRow {
+	t.Helper()
+	publishedAt, err := time.Parse(time.DateOnly, published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := s.DB.CreatePost(context.Background(), database.CreatePostParams{
+		ID:          uuid.New(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Title:       title,
+		Url:         feedURL + "#" + title,
+		Description: sql.NullString{String: "About " + title, Valid: true},
+		PublishedAt: publishedAt,
+		FeedID:      getFeed(t, s, feedURL).ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return post
+}
+
+// postTitles picks the titles out of a post listing, in order.
+func postTitles(out string) []string {
+	var titles []string
+	for _, block := range strings.Split(out, "---\n") {
+		if title, _, ok := strings.Cut(block, "\n"); ok && strings.Contains(block, "\nID: ") {
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
+
+// postsByTitle returns every post in the feeds user follows, read or not.
+func postsByTitle(t *testing.T, s *app.AppState, user string) map[string]database.GetPostsForUserRow {
+	t.Helper()
+	posts, err := s.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
+		UserID:      uuid.NullUUID{UUID: getUser(t, s, user).ID, Valid: true},
+		IncludeRead: true,
+		Limit:       100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byTitle := make(map[string]database.GetPostsForUserRow)
+	for _, post := range posts {
+		byTitle[post.Title] = post
+	}
+	return byTitle
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gator/internal/app"
+	"gator/internal/migrate"
+	"strconv"
+	"time"
+)
+
+// untrackedHint tells the owner of a hand-built database how to hand it over
+// to migrate.
+const untrackedHint = "run `gator migrate baseline <version>` with the version of the last migration already in its schema, then `gator migrate up`"
+
+func RegisterMigrateHandlers(c *app.Commands) {
+	c.Register("migrate", handleMigrate)
+}
+
+func handleMigrate(s *app.AppState, cmd app.Command) error {
+	if len(cmd.Args) < 1 {
+		fmt.Printf("Usage: %s up|down|status|version|baseline\n", cmd.Name)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	switch cmd.Args[0] {
+	case "up":
+		done, err := s.Migrator.Up(ctx)
+		for _, m := range done {
+			fmt.Printf("Applied %03d_%s\n", m.Version, m.Name)
+		}
+		if errors.Is(err, migrate.ErrUntracked) {
+			return fmt.Errorf("%v; %s", err, untrackedHint)
+		} else if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("Database is up to date")
+		}
+	case "down":
+		m, err := s.Migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("No migrations to roll back")
+			return nil
+		}
+		fmt.Printf("Rolled back %03d_%s\n", m.Version, m.Name)
+	case "status":
+		statuses, err := s.Migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt.Valid {
+				state = "applied " + status.AppliedAt.Time.Format(time.RFC1123)
+			}
+			fmt.Printf("%03d_%s: %s\n", status.Version, status.Name, state)
+		}
+	case "baseline":
+		if len(cmd.Args) < 2 {
+			fmt.Printf("Usage: %s baseline <version>\n", cmd.Name)
+			return nil
+		}
+		version, err := strconv.ParseInt(cmd.Args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", cmd.Args[1])
+		}
+		done, err := s.Migrator.Baseline(ctx, version)
+		if err != nil {
+			return err
+		}
+		for _, m := range done {
+			fmt.Printf("Recorded %03d_%s as applied\n", m.Version, m.Name)
+		}
+		if len(done) == 0 {
+			fmt.Printf("Migrations up to %03d were already recorded\n", version)
+		}
+	case "version":
+		version, err := s.Migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Schema version %d\n", version)
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q", cmd.Args[0])
+	}
+	return nil
+}
+
+// CheckSchema refuses to go on when migrations are pending, so commands never
+// run against a schema older than the queries they use. It only reads from the
+// database.
+func CheckSchema(s *app.AppState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pending, err := s.Migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check schema version: %v", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	untracked, err := s.Migrator.Untracked(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check schema version: %v", err)
+	}
+	if untracked {
+		return fmt.Errorf("database schema predates gator's migration tracking; %s", untrackedHint)
+	}
+	return fmt.Errorf("database schema is behind by %d migrations, run `gator migrate up` first", len(pending))
+}
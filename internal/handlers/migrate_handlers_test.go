@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"database/sql"
+	"gator/internal/app"
+	"gator/internal/config"
+	"gator/internal/storage"
+	"strings"
+	"testing"
+)
+
+func TestCheckSchemaUntracked(t *testing.T) {
+	path := t.TempDir() + "/gator.db"
+	store, migrator, err := storage.Open("sqlite://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := app.NewAppState(&config.Config{}, store, migrator)
+	c := app.NewCommands()
+	RegisterMigrateHandlers(c)
+
+	if err := CheckSchema(&s); err == nil || !strings.Contains(err.Error(), "migrate up") {
+		t.Errorf("empty database: got %v, want a hint to run migrate up", err)
+	}
+	if err := c.Run(&s, app.Command{Name: "migrate", Args: []string{"up"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSchema(&s); err != nil {
+		t.Fatal(err)
+	}
+
+	// Forget the recorded migrations, as if the schema had been built by
+	// hand.
+	raw, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	if _, err := raw.Exec("DROP TABLE goose_db_version"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CheckSchema(&s); err == nil || !strings.Contains(err.Error(), "migrate baseline") {
+		t.Errorf("untracked database: got %v, want a hint to run migrate baseline", err)
+	}
+	err = c.Run(&s, app.Command{Name: "migrate", Args: []string{"up"}})
+	if err == nil || !strings.Contains(err.Error(), "migrate baseline") {
+		t.Errorf("migrate up on an untracked database: got %v, want a hint to run migrate baseline", err)
+	}
+	var exists bool
+	if err := raw.QueryRow("SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE name = 'goose_db_version')").Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("schema check created the version table")
+	}
+
+	if err := c.Run(&s, app.Command{Name: "migrate", Args: []string{"baseline", "016"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSchema(&s); err != nil {
+		t.Errorf("after baseline: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"gator/internal/app"
+	"gator/internal/database"
+	"gator/internal/utils"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func RegisterOPMLHandlers(c *app.Commands) {
+	c.Register("import-opml", middlewareLoggedInWrapper(handleImportOPML))
+	c.Register("export-opml", handleExportOPML)
+}
+
+// handleImportOPML creates the feeds listed in an OPML file and follows them
+// for the current user, filing each under the folder its outline is nested
+// in and keeping its title when that differs from the feed's name. Everything
+// happens in one transaction so a failed import leaves no partial state
+// behind; entries without a usable xmlUrl are skipped and reported as failed.
+func handleImportOPML(s *app.AppState, cmd app.Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		fmt.Printf("Usage: %s <file>\n", cmd.Name)
+		return nil
+	}
+
+	file, err := os.Open(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", cmd.Args[0], err)
+	}
+	defer file.Close()
+	opml, err := utils.ParseOPML(file)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	var created, existing, failed int
+	err = s.DB.InTx(ctx, func(qtx database.Querier) error {
+		for _, sub := range opml.Subscriptions() {
+			if !isFeedURL(sub.XMLURL) {
+				fmt.Printf("Skipping %q: invalid xmlUrl %q\n", sub.Title, sub.XMLURL)
+				failed++
+				continue
+			}
+
+			feed, err := qtx.GetFeedByURL(ctx, sub.XMLURL)
+			if err == sql.ErrNoRows {
+				name := sub.Title
+				if name == "" {
+					name = sub.XMLURL
+				}
+				feed, err = qtx.CreateFeed(ctx, database.CreateFeedParams{
+					ID:        uuid.New(),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+					Name:      name,
+					Url:       sub.XMLURL,
+					UserID:    user.ID,
+					SiteUrl:   sql.NullString{String: sub.HTMLURL, Valid: sub.HTMLURL != ""},
+				})
+				if err != nil {
+					return fmt.Errorf("failed to add feed %s: %v", sub.XMLURL, err)
+				}
+				created++
+			} else if err != nil {
+				return fmt.Errorf("failed to look up feed %s: %v", sub.XMLURL, err)
+			} else {
+				existing++
+			}
+
+			var folderID uuid.NullUUID
+			if sub.Folder != "" {
+				folder, err := ensureFolder(ctx, qtx, user, sub.Folder)
+				if err != nil {
+					return err
+				}
+				folderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+			}
+
+			_, err = qtx.EnsureFeedFollow(ctx, database.EnsureFeedFollowParams{
+				ID:       uuid.New(),
+				UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+				FeedID:   uuid.NullUUID{UUID: feed.ID, Valid: true},
+				FolderID: folderID,
+				Title:    sql.NullString{String: sub.Title, Valid: sub.Title != "" && sub.Title != feed.Name},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to follow feed %s: %v", sub.XMLURL, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("import rolled back: %v", err)
+	}
+	fmt.Printf("Imported %s: %d created, %d existing, %d failed\n", cmd.Args[0], created, existing, failed)
+	return nil
+}
+
+// handleExportOPML writes the feeds a user follows as OPML 2.0, to stdout
+// unless -o is given. The user defaults to the one logged in.
+func handleExportOPML(s *app.AppState, cmd app.Command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	userName := fs.String("user", s.AppConfig.CurrentUserName, "user whose subscriptions are exported")
+	output := fs.String("o", "", "file to write instead of stdout")
+	if _, err := parseFlags(fs, cmd.Args); err != nil {
+		return err
+	}
+	if *userName == "" {
+		fmt.Println("User not logged in")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := s.DB.GetUser(ctx, sql.NullString{String: *userName, Valid: true})
+	if err != nil {
+		return fmt.Errorf("%s: %v", ErrGetUser, err)
+	}
+	feeds, err := s.DB.GetFollowedFeeds(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return fmt.Errorf("failed to get followed feeds: %v", err)
+	}
+
+	subs := make([]utils.OPMLSubscription, 0, len(feeds))
+	for _, feed := range feeds {
+		subs = append(subs, utils.OPMLSubscription{
+			Title:   feed.DisplayName,
+			XMLURL:  feed.Url,
+			HTMLURL: feed.SiteUrl.String,
+			Folder:  feed.FolderName.String,
+		})
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %v", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	title := fmt.Sprintf("%s's subscriptions in gator", *userName)
+	if err := utils.WriteOPML(w, title, subs); err != nil {
+		return fmt.Errorf("failed to write OPML: %v", err)
+	}
+	if *output != "" {
+		fmt.Printf("Exported %d feeds to %s\n", len(subs), *output)
+	}
+	return nil
+}
+
+func isFeedURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gator/internal/app"
+	"gator/internal/database"
+	"gator/internal/storage"
+	"gator/internal/utils"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const testOPML = `<?xml version="1.0"?>
+<opml version="2.0"><head><title>Export</title></head><body>
+  <outline type="rss" text="Blog" xmlUrl="https://blog.example/feed.xml" htmlUrl="https://blog.example/"/>
+  <outline text="Tech">
+    <outline text="Go">
+      <outline type="rss" text="The Go Blog" xmlURL="https://go.example/feed.atom"/>
+    </outline>
+    <outline type="rss" text="News" XMLURL="https://news.example/rss"/>
+  </outline>
+  <outline type="rss" text="No URL"/>
+  <outline type="rss" text="Not HTTP" xmlUrl="ftp://files.example/feed.xml"/>
+</body></opml>`
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// followedFeeds maps the URL of each feed user follows to the folder and
+// title it is shown under.
+func followedFeeds(t *testing.T, s *app.AppState, user database.User) map[string][2]string {
+	t.Helper()
+	feeds, err := s.DB.GetFollowedFeeds(context.Background(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string][2]string)
+	for _, feed := range feeds {
+		got[feed.Url] = [2]string{feed.FolderName.String, feed.DisplayName}
+	}
+	return got
+}
+
+func TestImportOPML(t *testing.T) {
+	path := writeTestFile(t, "subs.opml", testOPML)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		out := output(t, s, "import-opml", path)
+		if !strings.Contains(out, "3 created, 0 existing, 2 failed") {
+			t.Errorf("import printed %q", out)
+		}
+
+		want := map[string][2]string{
+			"https://blog.example/feed.xml": {"", "Blog"},
+			"https://go.example/feed.atom":  {"Go", "The Go Blog"},
+			"https://news.example/rss":      {"Tech", "News"},
+		}
+		alice := getUser(t, s, "alice")
+		if got := followedFeeds(t, s, alice); !reflect.DeepEqual(got, want) {
+			t.Errorf("alice follows %v, want %v", got, want)
+		}
+		if feed := getFeed(t, s, "https://blog.example/feed.xml"); feed.SiteUrl.String != "https://blog.example/" {
+			t.Errorf("site URL = %q", feed.SiteUrl.String)
+		}
+
+		// Importing again only adds what is missing, and another user's
+		// import reuses the feeds.
+		if out := output(t, s, "import-opml", path); !strings.Contains(out, "0 created, 3 existing, 2 failed") {
+			t.Errorf("second import printed %q", out)
+		}
+		mustRun(t, s, "register", "bob")
+		mustRun(t, s, "import-opml", path)
+		if got := followedFeeds(t, s, getUser(t, s, "bob")); !reflect.DeepEqual(got, want) {
+			t.Errorf("bob follows %v, want %v", got, want)
+		}
+		feeds, err := s.DB.GetFeeds(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(feeds) != 3 {
+			t.Errorf("got %d feeds, want 3", len(feeds))
+		}
+
+		if err := run(t, s, "import-opml", filepath.Join(t.TempDir(), "missing.opml")); err == nil {
+			t.Error("importing a missing file succeeded")
+		}
+	})
+}
+
+func TestImportOPMLTitle(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+		path := writeTestFile(t, "subs.opml", `<opml version="2.0"><body>
+  <outline type="rss" text="My blog" xmlUrl="`+srv.URL+`/feed.xml"/>
+</body></opml>`)
+		mustRun(t, s, "register", "bob")
+		mustRun(t, s, "import-opml", path)
+
+		// The feed keeps its name; bob's follow keeps the title from their file.
+		if feed := getFeed(t, s, srv.URL+"/feed.xml"); feed.Name != "Blog" {
+			t.Errorf("feed renamed to %q", feed.Name)
+		}
+		got := followedFeeds(t, s, getUser(t, s, "bob"))
+		if got[srv.URL+"/feed.xml"] != [2]string{"", "My blog"} {
+			t.Errorf("bob follows %v", got)
+		}
+	})
+}
+
+// failingStore makes CreateFeed fail for one URL inside transactions, and
+// CreateFeedFollow for every feed when failFollows is set, to check that a
+// command rolls back everything done before it.
+type failingStore struct {
+	storage.Store
+	failURL     string
+	failFollows bool
+}
+
+func (f failingStore) InTx(ctx context.Context, fn func(database.Querier) error) error {
+	return f.Store.InTx(ctx, func(q database.Querier) error {
+		return fn(failingQuerier{Querier: q, failURL: f.failURL, failFollows: f.failFollows})
+	})
+}
+
+type failingQuerier struct {
+	database.Querier
+	failURL     string
+	failFollows bool
+}
+
+func (q failingQuerier) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	if arg.Url == q.failURL {
+		return database.Feed{}, errors.New("disk full")
+	}
+	return q.Querier.CreateFeed(ctx, arg)
+}
+
+func (q failingQuerier) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	if q.failFollows {
+		return database.CreateFeedFollowRow{}, errors.New("disk full")
+	}
+	return q.Querier.CreateFeedFollow(ctx, arg)
+}
+
+func TestImportOPMLRollback(t *testing.T) {
+	path := writeTestFile(t, "subs.opml", testOPML)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		s.DB = failingStore{Store: s.DB, failURL: "https://news.example/rss"}
+		if err := run(t, s, "import-opml", path); err == nil {
+			t.Fatal("import succeeded")
+		}
+
+		ctx := context.Background()
+		if feeds, err := s.DB.GetFeeds(ctx); err != nil || len(feeds) != 0 {
+			t.Errorf("feeds left behind: %v, %v", feeds, err)
+		}
+		alice := getUser(t, s, "alice")
+		if got := followedURLs(t, s, alice); len(got) != 0 {
+			t.Errorf("follows left behind: %v", got)
+		}
+		_, err := s.DB.GetFolderByName(ctx, database.GetFolderByNameParams{UserID: alice.ID, Name: "Go"})
+		if err != sql.ErrNoRows {
+			t.Errorf("folder left behind: %v", err)
+		}
+	})
+}
+
+func TestExportOPML(t *testing.T) {
+	path := writeTestFile(t, "subs.opml", testOPML)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "import-opml", path)
+		mustRun(t, s, "register", "bob")
+
+		// -o writes a file; --user picks whose feeds, defaulting to the
+		// logged in user.
+		dir := t.TempDir()
+		mustRun(t, s, "export-opml", "--user", "alice", "-o", filepath.Join(dir, "alice.opml"))
+		mustRun(t, s, "export-opml", "-o", filepath.Join(dir, "bob.opml"))
+
+		want := []utils.OPMLSubscription{
+			{Title: "Blog", XMLURL: "https://blog.example/feed.xml", HTMLURL: "https://blog.example/"},
+			{Title: "News", XMLURL: "https://news.example/rss", Folder: "Tech"},
+			{Title: "The Go Blog", XMLURL: "https://go.example/feed.atom", Folder: "Go"},
+		}
+		if got := readOPML(t, filepath.Join(dir, "alice.opml")); !reflect.DeepEqual(got, want) {
+			t.Errorf("alice's export = %+v, want %+v", got, want)
+		}
+		if got := readOPML(t, filepath.Join(dir, "bob.opml")); len(got) != 0 {
+			t.Errorf("bob's export = %+v, want nothing", got)
+		}
+
+		// The export imports back into the same follows.
+		mustRun(t, s, "import-opml", filepath.Join(dir, "alice.opml"))
+		if got, want := followedFeeds(t, s, getUser(t, s, "bob")), followedFeeds(t, s, getUser(t, s, "alice")); !reflect.DeepEqual(got, want) {
+			t.Errorf("bob follows %v after importing alice's export, want %v", got, want)
+		}
+
+		if out := output(t, s, "export-opml", "--user", "alice"); !strings.Contains(out, `xmlUrl="https://blog.example/feed.xml"`) {
+			t.Errorf("export to stdout printed %q", out)
+		}
+		if err := run(t, s, "export-opml", "--user", "carol"); err == nil {
+			t.Error("exporting an unknown user succeeded")
+		}
+	})
+}
+
+func readOPML(t *testing.T, path string) []utils.OPMLSubscription {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	opml, err := utils.ParseOPML(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return opml.Subscriptions()
+}
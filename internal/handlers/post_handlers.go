@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"gator/internal/app"
+	"gator/internal/database"
+	"gator/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultBrowseLimit = 2
+	defaultSearchLimit = 10
+)
+
+func RegisterPostHandlers(c *app.Commands) {
+	c.Register("browse", middlewareLoggedInWrapper(handleBrowse))
+	c.Register("read", middlewareLoggedInWrapper(handleRead))
+	c.Register("unread", middlewareLoggedInWrapper(handleUnread))
+	c.Register("mark-all-read", middlewareLoggedInWrapper(handleMarkAllRead))
+	c.Register("star", middlewareLoggedInWrapper(handleStar))
+	c.Register("unstar", middlewareLoggedInWrapper(handleUnstar))
+	c.Register("starred", middlewareLoggedInWrapper(handleStarred))
+	c.Register("search", middlewareLoggedInWrapper(handleSearch))
+}
+
+// handleBrowse lists unread posts, newest first; --all includes read ones and
+// --folder keeps only the feeds filed in that folder.
+func handleBrowse(s *app.AppState, cmd app.Command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	all := fs.Bool("all", false, "include posts already read")
+	folderName := fs.String("folder", "", "only show posts from feeds in this folder")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+
+	limit := defaultBrowseLimit
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed < 1 {
+			return fmt.Errorf("invalid limit %q: must be a positive number", args[0])
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var folderID uuid.NullUUID
+	if *folderName != "" {
+		folder, err := getFolder(ctx, s.DB, user, *folderName)
+		if err != nil {
+			return err
+		}
+		folderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+	}
+
+	posts, err := s.DB.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:      uuid.NullUUID{UUID: user.ID, Valid: true},
+		IncludeRead: *all,
+		FolderID:    folderID,
+		Limit:       int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %v", err)
+	}
+
+	if len(posts) == 0 {
+		if *all {
+			fmt.Println("No posts found")
+		} else {
+			fmt.Println("No unread posts, use --all to include read ones")
+		}
+		return nil
+	}
+	for _, post := range posts {
+		published := post.PublishedAt.Format("Mon Jan 2 2006")
+		if post.PublishedAtUnknown {
+			published += " (date unknown, first seen)"
+		}
+		fmt.Printf("%s\n", post.Title)
+		fmt.Printf("Feed: %s\n", post.FeedName)
+		if post.Author.Valid {
+			fmt.Printf("Author: %s\n", post.Author.String)
+		}
+		fmt.Printf("Published: %s\n", published)
+		fmt.Printf("Link: %s\n", post.Url)
+		if post.ReadAt.Valid {
+			fmt.Printf("Read: %s\n", post.ReadAt.Time.Format("Mon Jan 2 2006"))
+		}
+		fmt.Printf("ID: %s\n", post.ID)
+		fmt.Println("---")
+	}
+	return nil
+}
+
+func handleRead(s *app.AppState, cmd app.Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		fmt.Printf("Usage: %s <post id or link>\n", cmd.Name)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	post, err := getPostForUser(ctx, s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	err = s.DB.MarkPostRead(ctx, database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark post read: %v", err)
+	}
+	fmt.Printf("Marked %q as read\n", post.Title)
+	return nil
+}
+
+func handleUnread(s *app.AppState, cmd app.Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		fmt.Printf("Usage: %s <post id or link>\n", cmd.Name)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	post, err := getPostForUser(ctx, s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark post unread: %v", err)
+	}
+	fmt.Printf("Marked %q as unread\n", post.Title)
+	return nil
+}
+
+// handleMarkAllRead marks every post in the user's feeds as read, narrowed to
+// one feed with --feed and to posts published before a date with --before.
+func handleMarkAllRead(s *app.AppState, cmd app.Command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	feedURL := fs.String("feed", "", "only mark posts of the feed with this URL")
+	before := fs.String("before", "", "only mark posts published before this date, e.g. 2024-05-01")
+	if _, err := parseFlags(fs, cmd.Args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	params := database.MarkAllPostsReadParams{
+		ReadAt: time.Now(),
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+	}
+	if *feedURL != "" {
+		if _, err := s.DB.GetFeedByURL(ctx, *feedURL); err != nil {
+			return fmt.Errorf("failed to get feed %s: %v", *feedURL, err)
+		}
+		params.FeedUrl = sql.NullString{String: *feedURL, Valid: true}
+	}
+	if *before != "" {
+		cutoff, ok := utils.ParseDate(*before)
+		if !ok {
+			return fmt.Errorf("invalid date %q for --before", *before)
+		}
+		params.Before = sql.NullTime{Time: cutoff, Valid: true}
+	}
+
+	marked, err := s.DB.MarkAllPostsRead(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to mark posts read: %v", err)
+	}
+	fmt.Printf("Marked %d posts as read\n", marked)
+	return nil
+}
+
+func handleStar(s *app.AppState, cmd app.Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		fmt.Printf("Usage: %s <post id or link>\n", cmd.Name)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	post, err := getPostForUser(ctx, s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	err = s.DB.StarPost(ctx, database.StarPostParams{
+		UserID:    user.ID,
+		PostID:    post.ID,
+		StarredAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to star post: %v", err)
+	}
+	fmt.Printf("Starred %q\n", post.Title)
+	return nil
+}
+
+func handleUnstar(s *app.AppState, cmd app.Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		fmt.Printf("Usage: %s <post id or link>\n", cmd.Name)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	removed, err := s.DB.UnstarPost(ctx, database.UnstarPostParams{
+		UserID: user.ID,
+		Post:   cmd.Args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unstar post: %v", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("no starred post %q", cmd.Args[0])
+	}
+	fmt.Printf("Unstarred %s\n", cmd.Args[0])
+	return nil
+}
+
+// handleStarred lists starred posts, most recently starred first. They stay
+// listed after the user unfollows their feed.
+func handleStarred(s *app.AppState, cmd app.Command, user database.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	posts, err := s.DB.GetStarredPostsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get starred posts: %v", err)
+	}
+	if len(posts) == 0 {
+		fmt.Println("No starred posts")
+		return nil
+	}
+	for _, post := range posts {
+		fmt.Printf("%s\n", post.Title)
+		fmt.Printf("Feed: %s\n", post.FeedName)
+		if post.Author.Valid {
+			fmt.Printf("Author: %s\n", post.Author.String)
+		}
+		fmt.Printf("Starred: %s\n", post.StarredAt.Format("Mon Jan 2 2006"))
+		fmt.Printf("Link: %s\n", post.Url)
+		fmt.Printf("ID: %s\n", post.ID)
+		fmt.Println("---")
+	}
+	return nil
+}
+
+// handleSearch runs a full-text search over the posts in the user's feeds.
+// The query takes web search syntax: "quoted phrases", -excluded words and or;
+// quote it, or put it after --, when it starts with an excluded word.
+func handleSearch(s *app.AppState, cmd app.Command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	feedURL := fs.String("feed", "", "only search posts of the feed with this URL")
+	since := fs.String("since", "", "only search posts published on or after this date, e.g. 2024-05-01")
+	limit := fs.Int("limit", defaultSearchLimit, "maximum number of results")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
+		fmt.Printf("Usage: %s <query> [--feed url] [--since date] [--limit n]\n", cmd.Name)
+		return nil
+	}
+	if *limit < 1 {
+		return fmt.Errorf("limit must be at least 1, got %d", *limit)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	params := database.SearchPostsParams{
+		Query:  strings.Join(args, " "),
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Limit:  int32(*limit),
+	}
+	if *feedURL != "" {
+		params.FeedUrl = sql.NullString{String: *feedURL, Valid: true}
+	}
+	if *since != "" {
+		cutoff, ok := utils.ParseDate(*since)
+		if !ok {
+			return fmt.Errorf("invalid date %q for --since", *since)
+		}
+		params.Since = sql.NullTime{Time: cutoff, Valid: true}
+	}
+
+	results, err := s.DB.SearchPosts(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to search posts: %v", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matching posts")
+		return nil
+	}
+	for _, post := range results {
+		published := post.PublishedAt.Format("Mon Jan 2 2006")
+		if post.PublishedAtUnknown {
+			published += " (date unknown, first seen)"
+		}
+		fmt.Printf("%s\n", post.Title)
+		fmt.Printf("Feed: %s\n", post.FeedName)
+		fmt.Printf("Published: %s\n", published)
+		fmt.Printf("Link: %s\n", post.Url)
+		fmt.Printf("  %s\n", post.Snippet)
+		fmt.Printf("ID: %s\n", post.ID)
+		fmt.Println("---")
+	}
+	return nil
+}
+
+// getPostForUser finds a post in the user's feeds by ID or link.
+func getPostForUser(ctx context.Context, s *app.AppState, user database.User, post string) (database.GetPostForUserRow, error) {
+	found, err := s.DB.GetPostForUser(ctx, database.GetPostForUserParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Post:   post,
+	})
+	if err == sql.ErrNoRows {
+		return found, fmt.Errorf("no post %q in the feeds you follow", post)
+	}
+	if err != nil {
+		return found, fmt.Errorf("failed to get post: %v", err)
+	}
+	return found, nil
+}
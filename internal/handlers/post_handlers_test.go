@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"gator/internal/app"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setUpPosts has alice follow /feed.xml with posts A, B and C, published on
+// the 1st, 2nd and 3rd of January, and /go.xml with post G from the 4th.
+func setUpPosts(t *testing.T, s *app.AppState, srvURL string) {
+	t.Helper()
+	mustRun(t, s, "register", "alice")
+	mustRun(t, s, "addfeed", srvURL+"/feed.xml")
+	mustRun(t, s, "addfeed", srvURL+"/go.xml")
+	addPost(t, s, srvURL+"/feed.xml", "A", "2024-01-01")
+	addPost(t, s, srvURL+"/feed.xml", "B", "2024-01-02")
+	addPost(t, s, srvURL+"/feed.xml", "C", "2024-01-03")
+	addPost(t, s, srvURL+"/go.xml", "G", "2024-01-04")
+}
+
+func TestBrowse(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		setUpPosts(t, s, srv.URL)
+
+		if got := postTitles(output(t, s, "browse")); !reflect.DeepEqual(got, []string{"G", "C"}) {
+			t.Errorf("browse = %v, want the two newest", got)
+		}
+		if got := postTitles(output(t, s, "browse", "10")); !reflect.DeepEqual(got, []string{"G", "C", "B", "A"}) {
+			t.Errorf("browse 10 = %v", got)
+		}
+		out := output(t, s, "browse", "10")
+		if !strings.Contains(out, "Feed: Blog: Go\n") || !strings.Contains(out, "Published: Thu Jan 4 2024\n") {
+			t.Errorf("browse printed %q", out)
+		}
+		if err := run(t, s, "browse", "none"); err == nil {
+			t.Error("browse accepted an invalid limit")
+		}
+
+		// Other users only see posts of the feeds they follow.
+		mustRun(t, s, "register", "bob")
+		mustRun(t, s, "follow", srv.URL+"/go.xml")
+		if got := postTitles(output(t, s, "browse", "10")); !reflect.DeepEqual(got, []string{"G"}) {
+			t.Errorf("bob's browse = %v", got)
+		}
+	})
+}
+
+func TestRead(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		setUpPosts(t, s, srv.URL)
+		posts := postsByTitle(t, s, "alice")
+
+		// By ID and by link.
+		mustRun(t, s, "read", posts["G"].ID.String())
+		mustRun(t, s, "read", posts["B"].Url)
+		mustRun(t, s, "read", posts["B"].Url)
+		if got := postTitles(output(t, s, "browse", "10")); !reflect.DeepEqual(got, []string{"C", "A"}) {
+			t.Errorf("unread = %v", got)
+		}
+		out := output(t, s, "browse", "--all", "10")
+		if got := postTitles(out); !reflect.DeepEqual(got, []string{"G", "C", "B", "A"}) {
+			t.Errorf("browse --all = %v", got)
+		}
+		if strings.Count(out, "Read: ") != 2 {
+			t.Errorf("browse --all marked %d posts read, want 2", strings.Count(out, "Read: "))
+		}
+
+		mustRun(t, s, "unread", posts["G"].Url)
+		mustRun(t, s, "unread", posts["B"].ID.String())
+		if got := postTitles(output(t, s, "browse", "10")); !reflect.DeepEqual(got, []string{"G", "C", "B", "A"}) {
+			t.Errorf("unread after unread = %v", got)
+		}
+
+		if err := run(t, s, "read", "https://nowhere.example/post"); err == nil {
+			t.Error("reading an unknown post succeeded")
+		}
+
+		// Reads are per user, and only cover posts of followed feeds.
+		mustRun(t, s, "read", posts["A"].ID.String())
+		mustRun(t, s, "register", "bob")
+		mustRun(t, s, "follow", srv.URL+"/feed.xml")
+		if got := postTitles(output(t, s, "browse", "10")); !reflect.DeepEqual(got, []string{"C", "B", "A"}) {
+			t.Errorf("bob's unread = %v", got)
+		}
+		if err := run(t, s, "read", posts["G"].ID.String()); err == nil {
+			t.Error("bob read a post of a feed they do not follow")
+		}
+	})
+}
+
+func TestMarkAllRead(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		setUpPosts(t, s, srv.URL)
+		mustRun(t, s, "read", postsByTitle(t, s, "alice")["A"].Url)
+
+		if out := output(t, s, "mark-all-read", "--before", "2024-01-03"); !strings.Contains(out, "Marked 1 posts as read") {
+			t.Errorf("--before printed %q", out)
+		}
+		if got := postTitles(output(t, s, "browse", "10")); !reflect.DeepEqual(got, []string{"G", "C"}) {
+			t.Errorf("unread after --before = %v", got)
+		}
+
+		if out := output(t, s, "mark-all-read", "--feed", srv.URL+"/go.xml"); !strings.Contains(out, "Marked 1 posts as read") {
+			t.Errorf("--feed printed %q", out)
+		}
+		if got := postTitles(output(t, s, "browse", "10")); !reflect.DeepEqual(got, []string{"C"}) {
+			t.Errorf("unread after --feed = %v", got)
+		}
+
+		if err := run(t, s, "mark-all-read", "--feed", srv.URL+"/unknown.xml"); err == nil {
+			t.Error("--feed accepted an unknown feed")
+		}
+		if err := run(t, s, "mark-all-read", "--before", "someday"); err == nil {
+			t.Error("--before accepted an invalid date")
+		}
+
+		if out := output(t, s, "mark-all-read"); !strings.Contains(out, "Marked 1 posts as read") {
+			t.Errorf("mark-all-read printed %q", out)
+		}
+		if out := output(t, s, "browse"); !strings.Contains(out, "No unread posts") {
+			t.Errorf("browse printed %q", out)
+		}
+	})
+}
+
+func TestStar(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		setUpPosts(t, s, srv.URL)
+		posts := postsByTitle(t, s, "alice")
+
+		mustRun(t, s, "star", posts["C"].ID.String())
+		mustRun(t, s, "star", posts["G"].Url)
+		mustRun(t, s, "star", posts["C"].Url)
+		if got := postTitles(output(t, s, "starred")); !reflect.DeepEqual(got, []string{"G", "C"}) {
+			t.Errorf("starred = %v, want the latest starred first", got)
+		}
+		if err := run(t, s, "star", "https://nowhere.example/post"); err == nil {
+			t.Error("starring an unknown post succeeded")
+		}
+
+		// Starred posts outlive the follow.
+		mustRun(t, s, "unfollow", srv.URL+"/go.xml")
+		out := output(t, s, "starred")
+		if got := postTitles(out); !reflect.DeepEqual(got, []string{"G", "C"}) {
+			t.Errorf("starred after unfollowing = %v", got)
+		}
+		if !strings.Contains(out, "Feed: Blog: Go\n") {
+			t.Errorf("starred printed %q", out)
+		}
+
+		mustRun(t, s, "unstar", posts["G"].Url)
+		if got := postTitles(output(t, s, "starred")); !reflect.DeepEqual(got, []string{"C"}) {
+			t.Errorf("starred after unstar = %v", got)
+		}
+		if err := run(t, s, "unstar", posts["G"].Url); err == nil {
+			t.Error("unstarring a post twice succeeded")
+		}
+
+		// Stars are per user.
+		mustRun(t, s, "register", "bob")
+		mustRun(t, s, "follow", srv.URL+"/feed.xml")
+		if out := output(t, s, "starred"); !strings.Contains(out, "No starred posts") {
+			t.Errorf("bob's starred printed %q", out)
+		}
+		if err := run(t, s, "unstar", posts["C"].ID.String()); err == nil {
+			t.Error("bob unstarred alice's star")
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+		mustRun(t, s, "addfeed", srv.URL+"/go.xml")
+		addPost(t, s, srv.URL+"/feed.xml", "Learning Go", "2024-01-01")
+		addPost(t, s, srv.URL+"/feed.xml", "Google news", "2024-01-02")
+		addPost(t, s, srv.URL+"/feed.xml", "Good food", "2024-01-03")
+		addPost(t, s, srv.URL+"/go.xml", "Go modules, go!", "2024-01-04")
+		addPost(t, s, srv.URL+"/go.xml", "Ready set go", "2024-01-05")
+
+		tests := []struct {
+			args []string
+			want []string
+		}{
+			// Whole words only, most matches first, then newest.
+			{[]string{"go"}, []string{"Go modules, go!", "Ready set go", "Learning Go"}},
+			{[]string{"GO", "--limit", "1"}, []string{"Go modules, go!"}},
+			{[]string{"go", "--feed", srv.URL + "/feed.xml"}, []string{"Learning Go"}},
+			{[]string{"--since", "2024-01-05", "go"}, []string{"Ready set go"}},
+			{[]string{`"set go"`}, []string{"Ready set go"}},
+			{[]string{"--", "go", "-modules"}, []string{"Ready set go", "Learning Go"}},
+			{[]string{"google", "or", "food"}, []string{"Good food", "Google news"}},
+			{[]string{"about", "news"}, []string{"Google news"}},
+		}
+		for _, tt := range tests {
+			if got := postTitles(output(t, s, "search", tt.args...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("search %q = %v, want %v", tt.args, got, tt.want)
+			}
+		}
+
+		if out := output(t, s, "search", `"go set"`); !strings.Contains(out, "No matching posts") {
+			t.Errorf("search for a phrase out of order printed %q", out)
+		}
+		if out := output(t, s, "search", "learning"); !strings.Contains(out, "  About **Learning** Go\n") {
+			t.Errorf("search printed %q, want the match highlighted", out)
+		}
+		if err := run(t, s, "search", "go", "--limit", "0"); err == nil {
+			t.Error("search accepted a limit of 0")
+		}
+		if err := run(t, s, "search", "go", "--since", "someday"); err == nil {
+			t.Error("search accepted an invalid date")
+		}
+	})
+}
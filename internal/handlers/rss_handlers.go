@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"gator/internal/app"
 	"gator/internal/database"
@@ -17,6 +18,7 @@ func RegisterRSSHandlers(c *app.Commands) {
 	c.Register("agg", handleAgg)
 	c.Register("addfeed", handleAddFeed)
 	c.Register("feeds", handleListFeeds)
+	c.Register("retry", handleRetry)
 }
 
 func handleFetchFeed(s *app.AppState, cmd app.Command) error {
@@ -25,36 +27,77 @@ func handleFetchFeed(s *app.AppState, cmd app.Command) error {
 }
 
 func handleAgg(s *app.AppState, cmd app.Command) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	concurrency := fs.Int("concurrency", defaultScrapeConcurrency, "number of feeds fetched in parallel")
+	timeout := fs.Duration("timeout", defaultScrapeTimeout, "time limit for fetching and storing a single feed")
+	lease := fs.Duration("lease", defaultScrapeLease, "how long a claimed feed stays reserved for this process")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
+		fmt.Printf("Usage: %s <time_between_reqs> [--concurrency n] [--timeout d] [--lease d]\n", cmd.Name)
+		return nil
+	}
+
+	timeBetweenRequests, err := time.ParseDuration(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %v", args[0], err)
+	}
+	if timeBetweenRequests <= 0 {
+		return fmt.Errorf("duration must be positive, got %s", timeBetweenRequests)
+	}
+	if *concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", *concurrency)
+	}
+	if *timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", *timeout)
+	}
+	if *lease <= *timeout+leaseMargin {
+		return fmt.Errorf("lease (%s) must be more than %s longer than timeout (%s)", *lease, leaseMargin, *timeout)
+	}
 
-	var rssFeed *utils.RSSFeed
-	rssFeed, err := utils.FetchFeed(ctx, "https://www.wagslane.dev/index.xml")
+	hostDelay, err := s.AppConfig.Fetch.HostDelay()
 	if err != nil {
-		return fmt.Errorf("failed to fetch feed: %v", err)
+		return err
 	}
-	fmt.Println("Title:", rssFeed.Channel.Title)
-	fmt.Println("Link:", rssFeed.Channel.Link)
-	fmt.Println("Description:", rssFeed.Channel.Description)
-	fmt.Println("Items:")
-	fmt.Printf("Title: %s\n", rssFeed.Channel.Item[0].Title)
-	fmt.Printf("Link: %s\n", rssFeed.Channel.Item[0].Link)
-	fmt.Printf("Description: %s\n", rssFeed.Channel.Item[0].Description)
+	limiter := utils.NewHostLimiter(hostDelay, s.AppConfig.Fetch.HostMaxConcurrency(), s.AppConfig.Fetch.RespectRobotsTxt)
 
+	fmt.Printf("Collecting feeds every %s with %d workers\n", timeBetweenRequests, *concurrency)
+	newScraper(s, *concurrency, *timeout, *lease, limiter).run(timeBetweenRequests)
 	return nil
-
 }
 
 func handleAddFeed(s *app.AppState, cmd app.Command) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	choice := fs.Int("choose", 0, "which of several discovered feeds to add, numbered from 1")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 {
+		fmt.Printf("Usage: %s <url> [name] [--choose n]\n", cmd.Name)
+		return nil
+	}
+
+	discovered, err := discoverFeed(args[0], *choice, s.AppConfig.Fetch.MaxBodyBytes)
+	if err != nil {
+		return err
+	}
+	if discovered == nil {
+		return nil
+	}
 
-	if len(cmd.Args) < 2 {
-		fmt.Printf("Usage: %s addfeed <name> <url>\n", cmd.Name)
+	name := discovered.Feed.Title
+	if len(args) > 1 {
+		name = args[1]
+	}
+	if name == "" {
+		return fmt.Errorf("feed %s has no title, pass a name: %s <url> <name>", discovered.URL, cmd.Name)
 	}
 
-	name := cmd.Args[0]
-	url := cmd.Args[1]
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
 	user, err := s.DB.GetUser(ctx, sql.NullString{
 		String: s.AppConfig.CurrentUserName,
@@ -64,35 +107,93 @@ func handleAddFeed(s *app.AppState, cmd app.Command) error {
 		return fmt.Errorf("failed to get user: %v", err)
 	}
 
-	_, err = s.DB.CreateFeed(ctx, database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      name,
-		Url:       url,
-		UserID:    user.ID,
+	// The feed and its follow go in together, so a failed follow leaves no
+	// feed behind that nobody follows.
+	err = s.DB.InTx(ctx, func(qtx database.Querier) error {
+		feed, err := qtx.CreateFeed(ctx, database.CreateFeedParams{
+			ID:          uuid.New(),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Name:        name,
+			Url:         discovered.URL,
+			UserID:      user.ID,
+			SiteUrl:     sql.NullString{String: discovered.Feed.Link, Valid: discovered.Feed.Link != ""},
+			Description: sql.NullString{String: discovered.Feed.Description, Valid: discovered.Feed.Description != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add feed: %v", err)
+		}
+		_, err = qtx.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:     uuid.New(),
+			UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+			FeedID: uuid.NullUUID{UUID: feed.ID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to follow feed: %v", err)
+		}
+		return nil
 	})
-	if err != nil {
-		return fmt.Errorf("failed to add feed: %v", err)
-	}
-	feed, err := s.DB.GetFeedByURL(ctx, url)
 	if err != nil {
 		return err
 	}
 
 	fmt.Printf("User %s added Feed %s successfully\n", s.AppConfig.CurrentUserName, name)
-	_, err = s.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
-		ID:     uuid.New(),
-		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
-		FeedID: uuid.NullUUID{UUID: feed.ID, Valid: true},
-	})
+	return nil
+}
+
+// discoverFeed resolves whatever the user pasted, a feed or a web page, to a
+// canonical feed URL and makes sure it parses as a feed. When the page offers
+// several feeds and none was chosen, they are listed and nil is returned.
+// Responses are capped at maxBytes, as in agg.
+func discoverFeed(pageURL string, choice int, maxBytes int64) (*utils.DiscoveredFeed, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	candidates, err := utils.DiscoverFeeds(ctx, pageURL, maxBytes)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to look for feeds at %s: %v", pageURL, err)
 	}
-	return nil
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no feed found at %s", pageURL)
+	}
+
+	var chosen utils.DiscoveredFeed
+	switch {
+	case choice > len(candidates):
+		return nil, fmt.Errorf("--choose %d is out of range, %s has %d feeds", choice, pageURL, len(candidates))
+	case choice > 0:
+		chosen = candidates[choice-1]
+	case len(candidates) == 1:
+		chosen = candidates[0]
+	default:
+		fmt.Printf("Found %d feeds at %s:\n", len(candidates), pageURL)
+		for i, candidate := range candidates {
+			fmt.Printf("%d. %s\n", i+1, candidate)
+		}
+		fmt.Println("Run addfeed again with --choose <n> to pick one")
+		return nil, nil
+	}
+
+	// Feeds advertised by <link> tags have not been fetched yet.
+	if err := chosen.Resolve(ctx, maxBytes); err != nil {
+		return nil, fmt.Errorf("%s is not a valid feed: %v", chosen.URL, err)
+	}
+	if chosen.URL != pageURL {
+		fmt.Printf("Found feed %s\n", chosen.URL)
+	}
+	return &chosen, nil
 }
 
 func handleListFeeds(s *app.AppState, cmd app.Command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	broken := fs.Bool("broken", false, "list only feeds that are failing or disabled")
+	if _, err := parseFlags(fs, cmd.Args); err != nil {
+		return err
+	}
+	if *broken {
+		return listBrokenFeeds(s)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -114,3 +215,50 @@ func handleListFeeds(s *app.AppState, cmd app.Command) error {
 	return nil
 
 }
+
+func listBrokenFeeds(s *app.AppState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	feeds, err := s.DB.GetBrokenFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get broken feeds: %v", err)
+	}
+	if len(feeds) == 0 {
+		fmt.Println("No broken feeds")
+		return nil
+	}
+
+	for _, feed := range feeds {
+		fmt.Printf("Feed: %s\n", feed.Name)
+		fmt.Printf("URL: %s\n", feed.Url)
+		fmt.Printf("Failures: %d\n", feed.ConsecutiveFailures)
+		fmt.Printf("Last error: %s\n", feed.LastError.String)
+		if feed.DisabledAt.Valid {
+			fmt.Printf("Disabled: %s\n", feed.DisabledAt.Time.Format(time.RFC1123))
+		} else if feed.NextFetchAt.Valid {
+			fmt.Printf("Next attempt: %s\n", feed.NextFetchAt.Time.Format(time.RFC1123))
+		}
+		fmt.Println("---")
+	}
+	return nil
+}
+
+func handleRetry(s *app.AppState, cmd app.Command) error {
+	if len(cmd.Args) < 1 {
+		fmt.Printf("Usage: %s <url>\n", cmd.Name)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	feed, err := s.DB.GetFeedByURL(ctx, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get feed: %v", err)
+	}
+	if err := s.DB.ResetFeedFetchErrors(ctx, feed.ID); err != nil {
+		return fmt.Errorf("failed to reset feed: %v", err)
+	}
+	fmt.Printf("Feed %s will be fetched again on the next agg cycle\n", feed.Url)
+	return nil
+}
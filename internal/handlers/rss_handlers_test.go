@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"gator/internal/app"
+	"reflect"
+	"testing"
+)
+
+func TestAddFeed(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+
+		feed := getFeed(t, s, srv.URL+"/feed.xml")
+		if feed.Name != "Blog" {
+			t.Errorf("name = %q, want the feed's title", feed.Name)
+		}
+		if feed.SiteUrl.String != "https://blog.example" || feed.Description.String != "Posts about Go" {
+			t.Errorf("site = %q, description = %q", feed.SiteUrl.String, feed.Description.String)
+		}
+		alice := getUser(t, s, "alice")
+		if feed.UserID != alice.ID {
+			t.Error("feed not owned by alice")
+		}
+		if got := followedURLs(t, s, alice); !reflect.DeepEqual(got, []string{feed.Url}) {
+			t.Errorf("alice follows %v, want the new feed", got)
+		}
+	})
+}
+
+func TestAddFeedRollback(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		s.DB = failingStore{Store: s.DB, failFollows: true}
+		if err := run(t, s, "addfeed", srv.URL+"/feed.xml"); err == nil {
+			t.Fatal("addfeed succeeded")
+		}
+
+		// The feed went away with the follow that failed.
+		if feeds, err := s.DB.GetFeeds(context.Background()); err != nil || len(feeds) != 0 {
+			t.Errorf("feeds left behind: %v, %v", feeds, err)
+		}
+	})
+}
+
+func TestAddFeedName(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml", "My blog")
+
+		if feed := getFeed(t, s, srv.URL+"/feed.xml"); feed.Name != "My blog" {
+			t.Errorf("name = %q, want My blog", feed.Name)
+		}
+	})
+}
+
+func TestAddFeedDiscovery(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL)
+
+		// The page links /old.xml, which redirects to the feed's real URL.
+		getFeed(t, s, srv.URL+"/feed.xml")
+	})
+}
+
+func TestAddFeedChoose(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+
+		mustRun(t, s, "addfeed", srv.URL+"/two")
+		if got := followedURLs(t, s, getUser(t, s, "alice")); len(got) != 0 {
+			t.Fatalf("added %v without --choose", got)
+		}
+		if err := run(t, s, "addfeed", srv.URL+"/two", "--choose", "3"); err == nil {
+			t.Error("--choose out of range succeeded")
+		}
+
+		mustRun(t, s, "addfeed", srv.URL+"/two", "--choose", "2")
+		if feed := getFeed(t, s, srv.URL+"/go.xml"); feed.Name != "Blog: Go" {
+			t.Errorf("name = %q, want Blog: Go", feed.Name)
+		}
+	})
+}
+
+func TestAddFeedMaxBodyBytes(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		s.AppConfig.Fetch.MaxBodyBytes = 64
+
+		if err := run(t, s, "addfeed", srv.URL+"/feed.xml"); err == nil {
+			t.Error("adding a feed over max_body_bytes succeeded")
+		}
+	})
+}
+
+func TestAddFeedRejects(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+
+		if err := run(t, s, "addfeed", srv.URL+"/feed.xml"); err == nil {
+			t.Error("adding the same feed twice succeeded")
+		}
+		if err := run(t, s, "addfeed", srv.URL+"/missing"); err == nil {
+			t.Error("adding a missing page succeeded")
+		}
+	})
+}
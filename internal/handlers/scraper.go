@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gator/internal/app"
+	"gator/internal/database"
+	"gator/internal/utils"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxConsecutiveFailures is how many fetches in a row may fail before a
+	// feed is disabled and left for `retry` to bring back.
+	maxConsecutiveFailures = 10
+	minFetchBackoff        = time.Minute
+	maxFetchBackoff        = 24 * time.Hour
+
+	defaultScrapeConcurrency = 1
+	defaultScrapeTimeout     = 10 * time.Second
+	defaultScrapeLease       = 5 * time.Minute
+	// leaseMargin is kept free at the end of a lease for recording the
+	// outcome of a fetch.
+	leaseMargin = 5 * time.Second
+)
+
+// scraper fetches feeds with a fixed pool of workers. Each tick it leases
+// the stalest due feeds for idle workers only, so a slow fetch never delays the
+// rest of the cycle. A feed whose host already has per_host_max_concurrency
+// fetches running is handed straight back rather than queued, so a host ties
+// up no more workers than that. Leases are held in the database, which keeps
+// any number of scraper processes from fetching the same feed at once.
+type scraper struct {
+	s           *app.AppState
+	id          string
+	concurrency int
+	timeout     time.Duration
+	lease       time.Duration
+	limiter     *utils.HostLimiter
+	jobs        chan scrapeJob
+
+	mu   sync.Mutex
+	busy int
+}
+
+// scrapeJob is a leased feed. claimedAt is taken before the claim, so the
+// lease is known to last until at least claimedAt plus the lease duration.
+type scrapeJob struct {
+	feed      database.Feed
+	claimedAt time.Time
+}
+
+func newScraper(s *app.AppState, concurrency int, timeout, lease time.Duration, limiter *utils.HostLimiter) *scraper {
+	return &scraper{
+		s:           s,
+		id:          scraperID(),
+		concurrency: concurrency,
+		timeout:     timeout,
+		lease:       lease,
+		limiter:     limiter,
+		jobs:        make(chan scrapeJob, concurrency),
+	}
+}
+
+// scraperID names this process in feeds.claimed_by.
+func scraperID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+}
+
+func (sc *scraper) run(interval time.Duration) {
+	for i := 0; i < sc.concurrency; i++ {
+		go sc.worker()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		if err := sc.dispatch(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+}
+
+func (sc *scraper) worker() {
+	for job := range sc.jobs {
+		if err := sc.scrape(job); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		if err := sc.release(job.feed); err != nil {
+			fmt.Printf("Failed to release lease on %s: %v\n", job.feed.Url, err)
+		}
+		sc.mu.Lock()
+		sc.busy--
+		sc.mu.Unlock()
+	}
+}
+
+// dispatch leases as many due feeds as there are idle workers and queues
+// them.
+func (sc *scraper) dispatch() error {
+	sc.mu.Lock()
+	idle := sc.concurrency - sc.busy
+	sc.mu.Unlock()
+	if idle <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	claimedAt := time.Now()
+	feeds, err := sc.s.DB.ClaimFeedsToFetch(ctx, database.ClaimFeedsToFetchParams{
+		ClaimedBy:    sc.id,
+		LeaseSeconds: sc.lease.Seconds(),
+		MaxFeeds:     int32(idle),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to claim feeds: %v", err)
+	}
+
+	sc.mu.Lock()
+	sc.busy += len(feeds)
+	sc.mu.Unlock()
+	for _, feed := range feeds {
+		sc.jobs <- scrapeJob{feed: feed, claimedAt: claimedAt}
+	}
+	return nil
+}
+
+// scrape fetches the feed if its host is free. Waiting out the host's
+// spacing delay happens before the per-feed timeout starts, so it is never
+// counted as a failed fetch, and ends early enough for the fetch to finish
+// within the lease. A feed whose host is busy is left unfetched; the worker
+// releases its lease at once, and as only a fetch moves last_fetched_at, it
+// keeps its place for the next cycle.
+func (sc *scraper) scrape(job scrapeJob) error {
+	feed := job.feed
+	waitUntil := job.claimedAt.Add(sc.lease - sc.timeout - leaseMargin)
+	ctx, cancel := context.WithDeadline(context.Background(), waitUntil)
+	defer cancel()
+
+	release, err := sc.limiter.TryAcquire(ctx, feed.Url)
+	if errors.Is(err, utils.ErrHostBusy) || errors.Is(err, context.DeadlineExceeded) {
+		fmt.Printf("Host of %s is busy, leaving it for the next cycle\n", feed.Url)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to schedule feed %s: %v", feed.Url, err)
+	}
+	defer release()
+	return scrapeAndRecord(sc.s, feed, sc.timeout)
+}
+
+func (sc *scraper) release(feed database.Feed) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return sc.s.DB.ReleaseFeedLease(ctx, database.ReleaseFeedLeaseParams{
+		ID:        feed.ID,
+		ClaimedBy: sc.id,
+	})
+}
+
+// scrapeAndRecord scrapes a feed and updates its error tracking columns
+// according to the outcome.
+func scrapeAndRecord(s *app.AppState, feed database.Feed, timeout time.Duration) error {
+	if err := markFeedFetched(s, feed); err != nil {
+		return err
+	}
+	scrapeErr := scrapeFeed(s, feed, timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if scrapeErr != nil {
+		if err := recordFetchFailure(ctx, s, feed, scrapeErr); err != nil {
+			fmt.Printf("Failed to record fetch failure for %s: %v\n", feed.Url, err)
+		}
+		return fmt.Errorf("failed to scrape feed %s: %v", feed.Url, scrapeErr)
+	}
+	if feed.ConsecutiveFailures > 0 {
+		if err := s.DB.ResetFeedFetchErrors(ctx, feed.ID); err != nil {
+			return fmt.Errorf("failed to reset fetch errors for %s: %v", feed.Url, err)
+		}
+	}
+	return nil
+}
+
+func markFeedFetched(s *app.AppState, feed database.Feed) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.DB.MarkFeedFetched(ctx, feed.ID); err != nil {
+		return fmt.Errorf("failed to mark feed %s fetched: %v", feed.Url, err)
+	}
+	return nil
+}
+
+// recordFetchFailure stores the error on the feed and schedules the next
+// attempt with exponential backoff, deferring further when the server sent
+// Retry-After. After maxConsecutiveFailures the feed is disabled.
+func recordFetchFailure(ctx context.Context, s *app.AppState, feed database.Feed, fetchErr error) error {
+	failures := int(feed.ConsecutiveFailures) + 1
+	delay := fetchBackoff(failures)
+
+	var statusErr *utils.HTTPStatusError
+	if errors.As(fetchErr, &statusErr) && statusErr.RetryAfter > delay {
+		delay = statusErr.RetryAfter
+	}
+
+	disable := failures >= maxConsecutiveFailures
+	if disable {
+		fmt.Printf("Feed %s disabled after %d consecutive failures\n", feed.Url, failures)
+	}
+
+	return s.DB.RecordFeedFetchFailure(ctx, database.RecordFeedFetchFailureParams{
+		LastError:      sql.NullString{String: fetchErr.Error(), Valid: true},
+		BackoffSeconds: delay.Seconds(),
+		Disable:        disable,
+		ID:             feed.ID,
+	})
+}
+
+// fetchBackoff doubles the wait for every consecutive failure, starting at
+// minFetchBackoff and capped at maxFetchBackoff.
+func fetchBackoff(failures int) time.Duration {
+	delay := minFetchBackoff
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= maxFetchBackoff {
+			return maxFetchBackoff
+		}
+	}
+	return delay
+}
+
+func scrapeFeed(s *app.AppState, feed database.Feed, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := utils.FetchFeedConditional(ctx, feed.Url, utils.FetchOptions{
+		ETag:         feed.Etag.String,
+		LastModified: feed.LastModified.String,
+		MaxBytes:     s.AppConfig.Fetch.MaxBodyBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	if result.NotModified {
+		fmt.Printf("Feed %s: not modified\n", feed.Name)
+		return nil
+	}
+
+	fetchedAt := time.Now().UTC()
+	saved := 0
+	for _, item := range result.Feed.Items {
+		if item.Link == "" {
+			continue
+		}
+		publishedAt, ok := utils.ParseDate(item.PubDate)
+		if !ok {
+			publishedAt = fetchedAt
+		}
+		_, err := s.DB.CreatePost(ctx, database.CreatePostParams{
+			ID:                 uuid.New(),
+			CreatedAt:          time.Now(),
+			UpdatedAt:          time.Now(),
+			Title:              item.Title,
+			Url:                item.Link,
+			Description:        sql.NullString{String: item.Description, Valid: item.Description != ""},
+			PublishedAt:        publishedAt,
+			PublishedAtUnknown: !ok,
+			FeedID:             feed.ID,
+			Author:             sql.NullString{String: item.Author, Valid: item.Author != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save post %s: %v", item.Link, err)
+		}
+		saved++
+	}
+
+	// Validators are only stored once every post is saved, so a failed run
+	// is retried in full rather than answered with 304.
+	err = s.DB.SetFeedCacheValidators(ctx, database.SetFeedCacheValidatorsParams{
+		ID:           feed.ID,
+		Etag:         sql.NullString{String: result.ETag, Valid: result.ETag != ""},
+		LastModified: sql.NullString{String: result.LastModified, Valid: result.LastModified != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save cache validators: %v", err)
+	}
+	fmt.Printf("Feed %s: saved %d posts\n", feed.Name, saved)
+	return nil
+}
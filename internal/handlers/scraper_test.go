@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"gator/internal/app"
+	"gator/internal/database"
+	"gator/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestScrapeBusyHost(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/feed.xml")
+		mustRun(t, s, "addfeed", srv.URL+"/go.xml")
+
+		limiter := utils.NewHostLimiter(0, 1, false)
+		sc := newScraper(s, 2, defaultScrapeTimeout, defaultScrapeLease, limiter)
+		if err := sc.dispatch(); err != nil {
+			t.Fatal(err)
+		}
+		first, second := <-sc.jobs, <-sc.jobs
+
+		// Hold the host's only slot, as a worker fetching first would.
+		release, err := limiter.TryAcquire(context.Background(), first.feed.Url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		if err := sc.scrape(second); err != nil {
+			t.Fatal(err)
+		}
+		if waited := time.Since(start); waited > time.Second {
+			t.Errorf("waited %v for a busy host", waited)
+		}
+		if err := sc.release(second.feed); err != nil {
+			t.Fatal(err)
+		}
+		feed := getFeed(t, s, second.feed.Url)
+		if feed.ClaimedBy.Valid || feed.LeaseExpiresAt.Valid {
+			t.Error("lease on the skipped feed still held")
+		}
+		if feed.LastFetchedAt.Valid {
+			t.Error("skipped feed marked fetched")
+		}
+
+		release()
+		if err := sc.scrape(first); err != nil {
+			t.Fatal(err)
+		}
+		if feed := getFeed(t, s, first.feed.Url); !feed.LastFetchedAt.Valid {
+			t.Error("fetched feed not marked fetched")
+		}
+	})
+}
+
+func TestScrapeFeedAuthor(t *testing.T) {
+	srv := newFeedServer(t)
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "addfeed", srv.URL+"/rdf.xml")
+		if err := scrapeFeed(s, getFeed(t, s, srv.URL+"/rdf.xml"), defaultScrapeTimeout); err != nil {
+			t.Fatal(err)
+		}
+
+		posts, err := s.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
+			UserID: uuid.NullUUID{UUID: getUser(t, s, "alice").ID, Valid: true},
+			Limit:  10,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(posts) != 1 || posts[0].Author.String != "Ada Lovelace" {
+			t.Errorf("got %+v, want one post by Ada Lovelace", posts)
+		}
+	})
+}
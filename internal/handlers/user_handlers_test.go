@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"gator/internal/app"
+	"gator/internal/config"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+
+		getUser(t, s, "alice")
+		if s.AppConfig.CurrentUserName != "alice" {
+			t.Errorf("current user = %q, want alice", s.AppConfig.CurrentUserName)
+		}
+		saved, err := config.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved.CurrentUserName != "alice" {
+			t.Errorf("saved current user = %q, want alice", saved.CurrentUserName)
+		}
+	})
+}
+
+func TestRegisterRejects(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+
+		if err := run(t, s, "register", "alice"); err == nil {
+			t.Error("registering alice twice succeeded")
+		}
+		if err := run(t, s, "register", "al"); err == nil {
+			t.Error("registering a two-letter name succeeded")
+		}
+	})
+}
+
+func TestLogin(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *app.AppState) {
+		mustRun(t, s, "register", "alice")
+		mustRun(t, s, "register", "bob")
+
+		mustRun(t, s, "login", "alice")
+		if s.AppConfig.CurrentUserName != "alice" {
+			t.Errorf("current user = %q, want alice", s.AppConfig.CurrentUserName)
+		}
+		if err := run(t, s, "login", "carol"); err == nil {
+			t.Error("logging in as an unknown user succeeded")
+		}
+	})
+}
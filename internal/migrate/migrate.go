@@ -0,0 +1,407 @@
+// Package migrate applies the goose-annotated migrations in sql/schema. It
+// keeps track of applied versions in goose's own goose_db_version table, so
+// Postgres databases that were migrated with the goose CLI are picked up as
+// they are.
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const versionTable = "goose_db_version"
+
+// ErrUntracked is returned by Up when the database already has tables but no
+// recorded migrations, as when its schema was created by hand. Baseline
+// records the migrations it already has.
+var ErrUntracked = errors.New("database has tables but no recorded migrations")
+
+// Dialect holds the SQL that differs between database backends. CountTables
+// counts the tables other than the version table.
+type Dialect struct {
+	CreateVersionTable string
+	VersionTableExists string
+	CountTables        string
+}
+
+var (
+	Postgres = Dialect{
+		CreateVersionTable: `CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+        id SERIAL PRIMARY KEY,
+        version_id BIGINT NOT NULL,
+        is_applied BOOLEAN NOT NULL,
+        tstamp TIMESTAMP DEFAULT NOW ()
+    )`,
+		VersionTableExists: `SELECT EXISTS (
+        SELECT 1 FROM information_schema.tables
+        WHERE table_schema = current_schema() AND table_name = '` + versionTable + `'
+    )`,
+		CountTables: `SELECT COUNT(*) FROM information_schema.tables
+    WHERE table_schema = current_schema() AND table_name <> '` + versionTable + `'`,
+	}
+	SQLite = Dialect{
+		CreateVersionTable: `CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        version_id INTEGER NOT NULL,
+        is_applied BOOLEAN NOT NULL,
+        tstamp TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+    )`,
+		VersionTableExists: `SELECT EXISTS (
+        SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = '` + versionTable + `'
+    )`,
+		CountTables: `SELECT COUNT(*) FROM sqlite_master
+    WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> '` + versionTable + `'`,
+	}
+)
+
+// Migrator applies one set of migrations to one database.
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, dialect Dialect, migrations []Migration) *Migrator {
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}
+}
+
+// Migration is one numbered schema change with its up and down SQL.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status pairs a migration with when it was applied, if it was.
+type Status struct {
+	Migration
+	AppliedAt sql.NullTime
+}
+
+// Load reads every NNN_name.sql file at the root of fsys, sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, file := range files {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: file name must look like 001_name.sql", file)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", file, prefix)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, file, version)
+		}
+		seen[version] = file
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		up, down, err := parseSections(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %v", file, err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, Up: up, Down: down})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// parseSections splits a migration at its "-- +goose Up" and "-- +goose Down"
+// annotations. Other goose annotations, such as StatementBegin, only matter
+// to goose's own statement splitter and are dropped; each section is sent to
+// the database as a single multi-statement query.
+func parseSections(sqlText string) (up, down string, err error) {
+	var current *strings.Builder
+	var upText, downText strings.Builder
+	foundUp := false
+
+	scanner := bufio.NewScanner(strings.NewReader(sqlText))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "-- +goose") {
+			switch strings.TrimSpace(strings.TrimPrefix(trimmed, "-- +goose")) {
+			case "Up":
+				current = &upText
+				foundUp = true
+			case "Down":
+				current = &downText
+			case "StatementBegin", "StatementEnd":
+			default:
+				return "", "", fmt.Errorf("unsupported annotation %q", trimmed)
+			}
+			continue
+		}
+		if current == nil {
+			if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return "", "", fmt.Errorf("SQL before the -- +goose Up annotation")
+			}
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+	if !foundUp {
+		return "", "", fmt.Errorf("missing -- +goose Up annotation")
+	}
+	return strings.TrimSpace(upText.String()), strings.TrimSpace(downText.String()), nil
+}
+
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	return m.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, m.dialect.CreateVersionTable); err != nil {
+			return err
+		}
+		// goose seeds the table with version 0; do the same so either tool
+		// can take over.
+		_, err := tx.ExecContext(ctx, `INSERT INTO `+versionTable+` (version_id, is_applied)
+SELECT 0, TRUE WHERE NOT EXISTS (SELECT 1 FROM `+versionTable+`)`)
+		return err
+	})
+}
+
+// applied returns when each applied version was applied. A version's latest
+// row decides its state, matching how goose reads the table. It only reads, so
+// a database without the version table has nothing applied.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	versions := make(map[int64]time.Time)
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, m.dialect.VersionTableExists).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look for %s: %v", versionTable, err)
+	}
+	if !exists {
+		return versions, nil
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version_id, is_applied, tstamp FROM `+versionTable+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			version   int64
+			isApplied bool
+			tstamp    sql.NullTime
+		)
+		if err := rows.Scan(&version, &isApplied, &tstamp); err != nil {
+			return nil, err
+		}
+		if version == 0 {
+			continue
+		}
+		if isApplied {
+			versions[version] = tstamp.Time
+		} else {
+			delete(versions, version)
+		}
+	}
+	return versions, rows.Err()
+}
+
+// Status reports every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	versions, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if at, ok := versions[migration.Version]; ok {
+			status.AppliedAt = sql.NullTime{Time: at, Valid: true}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Version returns the highest applied version, 0 for an empty database.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	versions, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var current int64
+	for version := range versions {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// Pending returns the migrations not yet applied, in order.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	versions, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := versions[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Untracked reports whether the database has tables but no applied
+// migrations, so Up would try to create tables that already exist.
+func (m *Migrator) Untracked(ctx context.Context) (bool, error) {
+	versions, err := m.applied(ctx)
+	if err != nil || len(versions) > 0 {
+		return false, err
+	}
+	var tables int
+	if err := m.db.QueryRowContext(ctx, m.dialect.CountTables).Scan(&tables); err != nil {
+		return false, fmt.Errorf("failed to count tables: %v", err)
+	}
+	return tables > 0, nil
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns the ones it applied. It stops at the first failure, and refuses to
+// start on an untracked database.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	untracked, err := m.Untracked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if untracked {
+		return nil, ErrUntracked
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", versionTable, err)
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		err := m.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO `+versionTable+` (version_id, is_applied) VALUES ($1, TRUE)`, migration.Version)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %03d_%s failed: %v", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Baseline records every migration up to and including version as applied
+// without running it, for a database whose schema was built by other means.
+// It returns the migrations it recorded.
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]Migration, error) {
+	known := false
+	for _, migration := range m.migrations {
+		known = known || migration.Version == version
+	}
+	if !known {
+		return nil, fmt.Errorf("no migration with version %d", version)
+	}
+	versions, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", versionTable, err)
+	}
+
+	var done []Migration
+	err = m.inTx(ctx, func(tx *sql.Tx) error {
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO `+versionTable+` (version_id, is_applied) VALUES ($1, TRUE)`, migration.Version)
+			if err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record migrations: %v", err)
+	}
+	return done, nil
+}
+
+// Down rolls back the most recently applied migration. It returns nil when
+// nothing is applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, nil
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version != current {
+			continue
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no down section", migration.Version, migration.Name)
+		}
+		err := m.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `DELETE FROM `+versionTable+` WHERE version_id = $1`, migration.Version)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("rolling back %03d_%s failed: %v", migration.Version, migration.Name, err)
+		}
+		return &migration, nil
+	}
+	return nil, fmt.Errorf("applied version %d has no migration file", current)
+}
+
+func (m *Migrator) inTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
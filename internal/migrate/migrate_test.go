@@ -0,0 +1,118 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testMigrations = fstest.MapFS{
+	"001_users.sql": {Data: []byte("-- +goose Up\nCREATE TABLE users (id INTEGER PRIMARY KEY);\n\n-- +goose Down\nDROP TABLE users;\n")},
+	"002_feeds.sql": {Data: []byte("-- +goose Up\nCREATE TABLE feeds (id INTEGER PRIMARY KEY);\n\n-- +goose Down\nDROP TABLE feeds;\n")},
+	"003_posts.sql": {Data: []byte("-- +goose Up\nCREATE TABLE posts (id INTEGER PRIMARY KEY);\n\n-- +goose Down\nDROP TABLE posts;\n")},
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", t.TempDir()+"/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrations, err := Load(testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewMigrator(db, SQLite, migrations), db
+}
+
+func versionTableExists(t *testing.T, db *sql.DB) bool {
+	t.Helper()
+	var exists bool
+	if err := db.QueryRow(SQLite.VersionTableExists).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
+
+func TestUp(t *testing.T) {
+	m, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 3 {
+		t.Errorf("got %d pending, want 3", len(pending))
+	}
+	if versionTableExists(t, db) {
+		t.Error("checking for pending migrations created the version table")
+	}
+
+	done, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 3 {
+		t.Errorf("applied %d, want 3", len(done))
+	}
+	if version, err := m.Version(ctx); err != nil || version != 3 {
+		t.Errorf("version = %d, %v, want 3", version, err)
+	}
+
+	if rolledBack, err := m.Down(ctx); err != nil || rolledBack.Version != 3 {
+		t.Fatalf("down = %v, %v", rolledBack, err)
+	}
+	if version, err := m.Version(ctx); err != nil || version != 2 {
+		t.Errorf("version = %d, %v, want 2", version, err)
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	m, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	// A schema built by hand up to 002, with no version table.
+	if _, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY); CREATE TABLE feeds (id INTEGER PRIMARY KEY);"); err != nil {
+		t.Fatal(err)
+	}
+	if untracked, err := m.Untracked(ctx); err != nil || !untracked {
+		t.Fatalf("untracked = %v, %v, want true", untracked, err)
+	}
+	if _, err := m.Up(ctx); !errors.Is(err, ErrUntracked) {
+		t.Fatalf("up on an untracked database: got %v, want ErrUntracked", err)
+	}
+	if versionTableExists(t, db) {
+		t.Error("refused up created the version table")
+	}
+
+	if _, err := m.Baseline(ctx, 9); err == nil {
+		t.Error("baseline accepted an unknown version")
+	}
+	done, err := m.Baseline(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 2 {
+		t.Errorf("recorded %d, want 2", len(done))
+	}
+	if done, err := m.Baseline(ctx, 2); err != nil || len(done) != 0 {
+		t.Errorf("second baseline recorded %d, %v", len(done), err)
+	}
+	if untracked, err := m.Untracked(ctx); err != nil || untracked {
+		t.Errorf("untracked = %v, %v after baseline", untracked, err)
+	}
+
+	done, err = m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0].Version != 3 {
+		t.Errorf("up applied %v, want only 003", done)
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gator/internal/database"
+	"gator/internal/migrate"
+	"gator/sql/schema"
+
+	_ "github.com/lib/pq"
+)
+
+type postgresStore struct {
+	*database.Queries
+	db *sql.DB
+}
+
+func openPostgres(dbURL string) (Store, *migrate.Migrator, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	migrations, err := migrate.Load(schema.FS)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load migrations: %v", err)
+	}
+	store := &postgresStore{Queries: database.New(db), db: db}
+	return store, migrate.NewMigrator(db, migrate.Postgres, migrations), nil
+}
+
+func (s *postgresStore) InTx(ctx context.Context, fn func(database.Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(s.Queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
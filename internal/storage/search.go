@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SearchQuery is a web-search style query, read the way websearch_to_tsquery
+// reads it for backends without full-text search: every word or "quoted
+// phrase" must appear, "or" between two of them accepts either, and a leading
+// - excludes a word or phrase. Words match whole and ignoring case, but
+// without the stemming Postgres does.
+type SearchQuery struct {
+	// required has one entry per term that must match, listing the phrases
+	// that satisfy it.
+	required [][]searchPhrase
+	excluded []searchPhrase
+}
+
+// searchPhrase is a run of lowercase words that must appear in order.
+type searchPhrase []string
+
+func ParseSearchQuery(query string) SearchQuery {
+	var q SearchQuery
+	or := false
+	rest := query
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return q
+		}
+		negate := strings.HasPrefix(rest, "-")
+		rest = strings.TrimPrefix(rest, "-")
+
+		var token string
+		quoted := strings.HasPrefix(rest, `"`)
+		if quoted {
+			token, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			token, rest = rest[:end], rest[end:]
+		}
+		if !quoted && !negate && strings.EqualFold(token, "or") {
+			or = true
+			continue
+		}
+
+		phrase := searchPhrase(searchWords(token))
+		switch {
+		case len(phrase) == 0:
+		case negate:
+			q.excluded = append(q.excluded, phrase)
+		case or && len(q.required) > 0:
+			last := len(q.required) - 1
+			q.required[last] = append(q.required[last], phrase)
+		default:
+			q.required = append(q.required, []searchPhrase{phrase})
+		}
+		or = false
+	}
+}
+
+// Empty reports whether the query has nothing a post could match, such as
+// when it only excludes words.
+func (q SearchQuery) Empty() bool {
+	return len(q.required) == 0
+}
+
+// Rank scores a post against the query, returning 0 when it does not match.
+// Every occurrence of a matching phrase counts, those in the title 1 and those
+// in the description 0.4, the weights search_vector gives them on Postgres.
+func (q SearchQuery) Rank(title, description string) float32 {
+	if q.Empty() {
+		return 0
+	}
+	titleWords, descriptionWords := searchWords(title), searchWords(description)
+	for _, phrase := range q.excluded {
+		if phrase.count(titleWords) > 0 || phrase.count(descriptionWords) > 0 {
+			return 0
+		}
+	}
+
+	var rank float32
+	for _, alternatives := range q.required {
+		var hits float32
+		for _, phrase := range alternatives {
+			hits += float32(phrase.count(titleWords)) + 0.4*float32(phrase.count(descriptionWords))
+		}
+		if hits == 0 {
+			return 0
+		}
+		rank += hits
+	}
+	return rank
+}
+
+const snippetWords = 30
+
+// Snippet cuts text down to the words around the first match and wraps every
+// word of the query in ** the way SearchPosts' ts_headline does.
+func (q SearchQuery) Snippet(text string) string {
+	terms := make(map[string]bool)
+	for _, alternatives := range q.required {
+		for _, phrase := range alternatives {
+			for _, word := range phrase {
+				terms[word] = true
+			}
+		}
+	}
+
+	words := strings.Fields(text)
+	first := -1
+	for i, word := range words {
+		for _, w := range searchWords(word) {
+			if terms[w] {
+				if first < 0 {
+					first = i
+				}
+				words[i] = "**" + word + "**"
+				break
+			}
+		}
+	}
+
+	start := 0
+	if first > snippetWords/3 {
+		start = first - snippetWords/3
+	}
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+	return strings.Join(words[start:end], " ")
+}
+
+// count returns how many times the phrase occurs in words.
+func (p searchPhrase) count(words []string) int {
+	n := 0
+	for i := 0; i+len(p) <= len(words); i++ {
+		match := true
+		for j, word := range p {
+			if words[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			n++
+		}
+	}
+	return n
+}
+
+// searchWords splits text into lowercase words of letters and digits.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"gator/internal/database"
+	"gator/internal/migrate"
+	"gator/sql/schema"
+	"regexp"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteTimeFormat is how timestamps are stored in SQLite. Every value is UTC
+// with a fixed number of fractional digits, so comparing the strings compares
+// the times.
+const sqliteTimeFormat = "2006-01-02 15:04:05.000000000-07:00"
+
+const sqliteDriverName = "sqlite3_gator"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		// The shared queries call NOW(), which SQLite lacks, and SearchPosts
+		// ranks posts with search_rank in place of Postgres' ts_rank.
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := conn.RegisterFunc("now", func() string {
+				return formatSQLiteTime(time.Now())
+			}, false); err != nil {
+				return err
+			}
+			return conn.RegisterFunc("search_rank", func(query, title, description string) float64 {
+				return float64(ParseSearchQuery(query).Rank(title, description))
+			}, true)
+		},
+	})
+}
+
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// sqliteStore runs the generated queries through sqliteDB and overrides the
+// ones whose SQL only Postgres understands.
+type sqliteStore struct {
+	*database.Queries
+	db sqliteDB
+	// conn is nil when the store is bound to a transaction.
+	conn *sql.DB
+}
+
+func openSQLite(path string) (Store, *migrate.Migrator, error) {
+	db, err := sql.Open(sqliteDriverName, path+"?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, nil, err
+	}
+	// A single connection serialises writers, which is what SQLite wants,
+	// and keeps commands from tripping over "database is locked".
+	db.SetMaxOpenConns(1)
+
+	migrations, err := migrate.Load(schema.SQLite())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load migrations: %v", err)
+	}
+	return newSQLiteStore(db, db), migrate.NewMigrator(db, migrate.SQLite, migrations), nil
+}
+
+func newSQLiteStore(db database.DBTX, conn *sql.DB) *sqliteStore {
+	adapted := sqliteDB{db: db}
+	return &sqliteStore{Queries: database.New(adapted), db: adapted, conn: conn}
+}
+
+func (s *sqliteStore) InTx(ctx context.Context, fn func(database.Querier) error) error {
+	if s.conn == nil {
+		return fn(s)
+	}
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(newSQLiteStore(tx, nil)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const sqliteClaimFeedsToFetch = `
+UPDATE feeds
+SET claimed_by = ?1,
+    lease_expires_at = ?2,
+    updated_at = now()
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL
+    AND (next_fetch_at IS NULL OR next_fetch_at <= now())
+    AND (lease_expires_at IS NULL OR lease_expires_at <= now())
+    ORDER BY last_fetched_at ASC NULLS FIRST
+    LIMIT ?3
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, last_error, consecutive_failures, next_fetch_at, disabled_at, claimed_by, lease_expires_at, site_url, description
+`
+
+// ClaimFeedsToFetch needs no SKIP LOCKED on SQLite: writers are serialised,
+// so the UPDATE cannot race with another instance's claim.
+func (s *sqliteStore) ClaimFeedsToFetch(ctx context.Context, arg database.ClaimFeedsToFetchParams) ([]database.Feed, error) {
+	leaseExpiresAt := time.Now().Add(time.Duration(arg.LeaseSeconds * float64(time.Second)))
+	rows, err := s.db.QueryContext(ctx, sqliteClaimFeedsToFetch, arg.ClaimedBy, leaseExpiresAt, arg.MaxFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.Feed
+	for rows.Next() {
+		var i database.Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.NextFetchAt,
+			&i.DisabledAt,
+			&i.ClaimedBy,
+			&i.LeaseExpiresAt,
+			&i.SiteUrl,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sqliteRecordFeedFetchFailure = `
+UPDATE feeds
+SET last_error = ?1,
+    consecutive_failures = consecutive_failures + 1,
+    next_fetch_at = ?2,
+    disabled_at = CASE WHEN ?3 THEN now() END,
+    updated_at = now()
+WHERE id = ?4
+`
+
+// RecordFeedFetchFailure works out next_fetch_at in Go, as SQLite has no
+// make_interval. Times are stored in UTC, so this agrees with now().
+func (s *sqliteStore) RecordFeedFetchFailure(ctx context.Context, arg database.RecordFeedFetchFailureParams) error {
+	nextFetchAt := time.Now().Add(time.Duration(arg.BackoffSeconds * float64(time.Second)))
+	_, err := s.db.ExecContext(ctx, sqliteRecordFeedFetchFailure, arg.LastError, nextFetchAt, arg.Disable, arg.ID)
+	return err
+}
+
+const sqliteCreateFeedFollow = `
+INSERT INTO feed_follow (id, user_id, feed_id, folder_id)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+const sqliteGetFeedFollowRow = `
+SELECT
+    feed_follow.id, feed_follow.created_at, feed_follow.updated_at, feed_follow.user_id, feed_follow.feed_id, feed_follow.folder_id, feed_follow.title,
+    users.name as user_name,
+    feeds.name as feed_name
+FROM feed_follow
+JOIN users ON users.id = feed_follow.user_id
+JOIN feeds ON feeds.id = feed_follow.feed_id
+WHERE feed_follow.id = ?1
+`
+
+// CreateFeedFollow splits the Postgres query in two, since SQLite does not
+// allow an INSERT inside a WITH clause.
+func (s *sqliteStore) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	var i database.CreateFeedFollowRow
+	if _, err := s.db.ExecContext(ctx, sqliteCreateFeedFollow, arg.ID, arg.UserID, arg.FeedID, arg.FolderID); err != nil {
+		return i, err
+	}
+	row := s.db.QueryRowContext(ctx, sqliteGetFeedFollowRow, arg.ID)
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.Title,
+		&i.UserName,
+		&i.FeedName,
+	)
+	return i, err
+}
+
+const sqliteSearchPosts = `
+SELECT posts.id, posts.title, posts.url, posts.published_at, posts.published_at_unknown,
+    coalesce(feed_follow.title, feeds.name) AS feed_name,
+    search_rank(?4, posts.title, coalesce(posts.description, '')) AS rank,
+    coalesce(posts.description, posts.title) AS body
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follow ON feed_follow.feed_id = posts.feed_id
+WHERE feed_follow.user_id = ?1
+AND (?2 IS NULL OR feeds.url = ?2)
+AND (?3 IS NULL OR posts.published_at >= ?3)
+AND rank > 0
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT ?5
+`
+
+// SearchPosts ranks posts with search_rank, as SQLite has no tsvector. It
+// reads the query like websearch_to_tsquery, matching whole words and
+// phrases but without stemming.
+func (s *sqliteStore) SearchPosts(ctx context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error) {
+	query := ParseSearchQuery(arg.Query)
+	if query.Empty() {
+		return nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqliteSearchPosts, arg.UserID, arg.FeedUrl, arg.Since, arg.Query, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.SearchPostsRow
+	for rows.Next() {
+		var i database.SearchPostsRow
+		var rank float64
+		var body string
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.PublishedAtUnknown,
+			&i.FeedName,
+			&rank,
+			&body,
+		); err != nil {
+			return nil, err
+		}
+		i.Rank = float32(rank)
+		i.Snippet = query.Snippet(body)
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// sqliteDB adapts the generated queries to SQLite: Postgres $N placeholders
+// become ?N, which SQLite numbers the same way, ::type casts are dropped, and
+// timestamps are bound in sqliteTimeFormat.
+type sqliteDB struct {
+	db database.DBTX
+}
+
+var (
+	postgresPlaceholder = regexp.MustCompile(`\$(\d+)`)
+	postgresCast        = regexp.MustCompile(`::\w+`)
+)
+
+func (a sqliteDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return a.db.ExecContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func (a sqliteDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return a.db.PrepareContext(ctx, sqliteQuery(query))
+}
+
+func (a sqliteDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return a.db.QueryContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func (a sqliteDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return a.db.QueryRowContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func sqliteQuery(query string) string {
+	query = postgresCast.ReplaceAllString(query, "")
+	return postgresPlaceholder.ReplaceAllString(query, "?$1")
+}
+
+func sqliteArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = formatSQLiteTime(v)
+		case sql.NullTime:
+			if v.Valid {
+				converted[i] = formatSQLiteTime(v.Time)
+			}
+		default:
+			converted[i] = v
+		}
+	}
+	return converted
+}
@@ -0,0 +1,32 @@
+// Package storage picks the database backend from db_url and hands out a
+// Store for it. Postgres and SQLite share the sqlc-generated queries; the few
+// that use Postgres-only SQL are rewritten for SQLite in sqlite.go.
+package storage
+
+import (
+	"context"
+	"gator/internal/database"
+	"gator/internal/migrate"
+	"strings"
+)
+
+const sqliteScheme = "sqlite://"
+
+// Store is everything handlers need from the database. storetest.New returns
+// an in-memory implementation for unit tests.
+type Store interface {
+	database.Querier
+	// InTx runs fn inside a transaction, committing when it returns nil and
+	// rolling back otherwise.
+	InTx(ctx context.Context, fn func(database.Querier) error) error
+}
+
+// Open connects to the database named by dbURL. sqlite:///path/to/gator.db
+// selects SQLite; any other URL is handed to the Postgres driver. The returned
+// migrator applies the schema matching the backend.
+func Open(dbURL string) (Store, *migrate.Migrator, error) {
+	if strings.HasPrefix(dbURL, sqliteScheme) {
+		return openSQLite(strings.TrimPrefix(dbURL, sqliteScheme))
+	}
+	return openPostgres(dbURL)
+}
@@ -0,0 +1,215 @@
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"gator/internal/database"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	follow, err := s.insertFeedFollow(arg.ID, arg.UserID, arg.FeedID, arg.FolderID, sql.NullString{})
+	if err != nil {
+		return database.CreateFeedFollowRow{}, err
+	}
+	row := database.CreateFeedFollowRow{
+		ID:        follow.ID,
+		CreatedAt: follow.CreatedAt,
+		UpdatedAt: follow.UpdatedAt,
+		UserID:    follow.UserID,
+		FeedID:    follow.FeedID,
+		FolderID:  follow.FolderID,
+		Title:     follow.Title,
+	}
+	// The Postgres query joins users and feeds, so a follow with a NULL side
+	// comes back as no row.
+	userIdx, userOK := s.userIndex(follow.UserID.UUID)
+	feedIdx, feedOK := s.feedIndex(follow.FeedID.UUID)
+	if follow.UserID.Valid && follow.FeedID.Valid && userOK && feedOK {
+		row.UserName = s.users[userIdx].Name
+		row.FeedName = s.feeds[feedIdx].Name
+	}
+	return row, nil
+}
+
+func (s *Store) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.follows[:0]
+	for _, follow := range s.follows {
+		if follow.UserID.Valid && follow.UserID == arg.UserID && follow.FeedID.Valid && follow.FeedID == arg.FeedID {
+			continue
+		}
+		kept = append(kept, follow)
+	}
+	s.follows = kept
+	return nil
+}
+
+func (s *Store) EnsureFeedFollow(ctx context.Context, arg database.EnsureFeedFollowParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.followIndex(arg.UserID, arg.FeedID) >= 0 {
+		return 0, nil
+	}
+	if _, err := s.insertFeedFollow(arg.ID, arg.UserID, arg.FeedID, arg.FolderID, arg.Title); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (s *Store) GetFeedFollowsForUser(ctx context.Context, userID uuid.NullUUID) ([]database.FeedFollow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var follows []database.FeedFollow
+	for _, follow := range s.follows {
+		if userID.Valid && follow.UserID == userID {
+			follows = append(follows, follow)
+		}
+	}
+	return follows, nil
+}
+
+func (s *Store) GetFollowedFeeds(ctx context.Context, userID uuid.NullUUID) ([]database.GetFollowedFeedsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var feeds []database.GetFollowedFeedsRow
+	for _, follow := range s.follows {
+		if !userID.Valid || follow.UserID != userID {
+			continue
+		}
+		i, ok := s.feedIndex(follow.FeedID.UUID)
+		if !ok || !follow.FeedID.Valid {
+			continue
+		}
+		feed := s.feeds[i]
+		row := database.GetFollowedFeedsRow{
+			ID:                  feed.ID,
+			CreatedAt:           feed.CreatedAt,
+			UpdatedAt:           feed.UpdatedAt,
+			Name:                feed.Name,
+			Url:                 feed.Url,
+			UserID:              feed.UserID,
+			LastFetchedAt:       feed.LastFetchedAt,
+			Etag:                feed.Etag,
+			LastModified:        feed.LastModified,
+			LastError:           feed.LastError,
+			ConsecutiveFailures: feed.ConsecutiveFailures,
+			NextFetchAt:         feed.NextFetchAt,
+			DisabledAt:          feed.DisabledAt,
+			ClaimedBy:           feed.ClaimedBy,
+			LeaseExpiresAt:      feed.LeaseExpiresAt,
+			SiteUrl:             feed.SiteUrl,
+			Description:         feed.Description,
+			DisplayName:         feed.Name,
+		}
+		if follow.Title.Valid {
+			row.DisplayName = follow.Title.String
+		}
+		if j, ok := s.folderIndex(follow.FolderID.UUID); ok && follow.FolderID.Valid {
+			row.FolderName = sql.NullString{String: s.folders[j].Name, Valid: true}
+		}
+		feeds = append(feeds, row)
+	}
+	sort.SliceStable(feeds, func(a, b int) bool {
+		return feeds[a].DisplayName < feeds[b].DisplayName
+	})
+	return feeds, nil
+}
+
+func (s *Store) SetFeedFollowFolder(ctx context.Context, arg database.SetFeedFollowFolderParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.followIndex(arg.UserID, arg.FeedID)
+	if i < 0 {
+		return 0, nil
+	}
+	if _, ok := s.folderIndex(arg.FolderID.UUID); arg.FolderID.Valid && !ok {
+		return 0, ErrConstraint
+	}
+	s.follows[i].FolderID = arg.FolderID
+	s.follows[i].UpdatedAt = time.Now()
+	return 1, nil
+}
+
+func (s *Store) SetFeedFollowTitle(ctx context.Context, arg database.SetFeedFollowTitleParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.followIndex(arg.UserID, arg.FeedID)
+	if i < 0 {
+		return 0, nil
+	}
+	s.follows[i].Title = arg.Title
+	s.follows[i].UpdatedAt = time.Now()
+	return 1, nil
+}
+
+// insertFeedFollow checks the keys of a new follow and stores it. The caller
+// holds s.mu.
+func (s *Store) insertFeedFollow(id uuid.UUID, userID, feedID, folderID uuid.NullUUID, title sql.NullString) (database.FeedFollow, error) {
+	for _, follow := range s.follows {
+		if follow.ID == id {
+			return database.FeedFollow{}, ErrConstraint
+		}
+	}
+	if s.followIndex(userID, feedID) >= 0 {
+		return database.FeedFollow{}, ErrConstraint
+	}
+	if _, ok := s.userIndex(userID.UUID); userID.Valid && !ok {
+		return database.FeedFollow{}, ErrConstraint
+	}
+	if _, ok := s.feedIndex(feedID.UUID); feedID.Valid && !ok {
+		return database.FeedFollow{}, ErrConstraint
+	}
+	if _, ok := s.folderIndex(folderID.UUID); folderID.Valid && !ok {
+		return database.FeedFollow{}, ErrConstraint
+	}
+
+	now := time.Now()
+	follow := database.FeedFollow{
+		ID:        id,
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    userID,
+		FeedID:    feedID,
+		FolderID:  folderID,
+		Title:     title,
+	}
+	s.follows = append(s.follows, follow)
+	return follow, nil
+}
+
+// followIndex finds the follow for a user and feed, or returns -1. NULLs never
+// match, as in the UNIQUE (user_id, feed_id) constraint.
+func (s *Store) followIndex(userID, feedID uuid.NullUUID) int {
+	if !userID.Valid || !feedID.Valid {
+		return -1
+	}
+	for i, follow := range s.follows {
+		if follow.UserID == userID && follow.FeedID == feedID {
+			return i
+		}
+	}
+	return -1
+}
+
+// feedTitle is the name a user sees for a feed: their own title for it when
+// they follow it and set one, else the feed's name. The caller holds s.mu.
+func (s *Store) feedTitle(userID uuid.NullUUID, feed database.Feed) string {
+	if i := s.followIndex(userID, nullUUID(feed.ID)); i >= 0 && s.follows[i].Title.Valid {
+		return s.follows[i].Title.String
+	}
+	return feed.Name
+}
@@ -0,0 +1,204 @@
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"gator/internal/database"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) ClaimFeedsToFetch(ctx context.Context, arg database.ClaimFeedsToFetchParams) ([]database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var due []int
+	for i, feed := range s.feeds {
+		if feed.DisabledAt.Valid {
+			continue
+		}
+		if feed.NextFetchAt.Valid && feed.NextFetchAt.Time.After(now) {
+			continue
+		}
+		if feed.LeaseExpiresAt.Valid && feed.LeaseExpiresAt.Time.After(now) {
+			continue
+		}
+		due = append(due, i)
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		fa, fb := s.feeds[due[a]].LastFetchedAt, s.feeds[due[b]].LastFetchedAt
+		if !fa.Valid || !fb.Valid {
+			return !fa.Valid && fb.Valid
+		}
+		return fa.Time.Before(fb.Time)
+	})
+	if len(due) > int(arg.MaxFeeds) {
+		due = due[:arg.MaxFeeds]
+	}
+
+	leaseExpiresAt := now.Add(time.Duration(arg.LeaseSeconds * float64(time.Second)))
+	var claimed []database.Feed
+	for _, i := range due {
+		feed := &s.feeds[i]
+		feed.ClaimedBy = sql.NullString{String: arg.ClaimedBy, Valid: true}
+		feed.LeaseExpiresAt = sql.NullTime{Time: leaseExpiresAt, Valid: true}
+		feed.UpdatedAt = now
+		claimed = append(claimed, *feed)
+	}
+	return claimed, nil
+}
+
+func (s *Store) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.feedIndex(arg.ID); ok {
+		return database.Feed{}, ErrConstraint
+	}
+	if _, ok := s.userIndex(arg.UserID); !ok {
+		return database.Feed{}, ErrConstraint
+	}
+	for _, feed := range s.feeds {
+		if feed.Url == arg.Url {
+			return database.Feed{}, ErrConstraint
+		}
+	}
+	feed := database.Feed{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Name:        arg.Name,
+		Url:         arg.Url,
+		UserID:      arg.UserID,
+		SiteUrl:     arg.SiteUrl,
+		Description: arg.Description,
+	}
+	s.feeds = append(s.feeds, feed)
+	return feed, nil
+}
+
+func (s *Store) GetBrokenFeeds(ctx context.Context) ([]database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var broken []database.Feed
+	for _, feed := range s.feeds {
+		if feed.ConsecutiveFailures > 0 || feed.DisabledAt.Valid {
+			broken = append(broken, feed)
+		}
+	}
+	sort.SliceStable(broken, func(a, b int) bool {
+		if broken[a].ConsecutiveFailures != broken[b].ConsecutiveFailures {
+			return broken[a].ConsecutiveFailures > broken[b].ConsecutiveFailures
+		}
+		return broken[a].Name < broken[b].Name
+	})
+	return broken, nil
+}
+
+func (s *Store) GetFeed(ctx context.Context, id uuid.UUID) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.feedIndex(id)
+	if !ok {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return s.feeds[i], nil
+}
+
+func (s *Store) GetFeedByURL(ctx context.Context, url string) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, feed := range s.feeds {
+		if feed.Url == url {
+			return feed, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (s *Store) GetFeedNameById(ctx context.Context, id uuid.UUID) (string, error) {
+	feed, err := s.GetFeed(ctx, id)
+	return feed.Name, err
+}
+
+func (s *Store) GetFeeds(ctx context.Context) ([]database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]database.Feed(nil), s.feeds...), nil
+}
+
+func (s *Store) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
+	return s.updateFeed(id, func(feed *database.Feed) {
+		feed.LastFetchedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	})
+}
+
+func (s *Store) RecordFeedFetchFailure(ctx context.Context, arg database.RecordFeedFetchFailureParams) error {
+	return s.updateFeed(arg.ID, func(feed *database.Feed) {
+		feed.LastError = arg.LastError
+		feed.ConsecutiveFailures++
+		now := time.Now()
+		feed.NextFetchAt = sql.NullTime{Time: now.Add(time.Duration(arg.BackoffSeconds * float64(time.Second))), Valid: true}
+		feed.DisabledAt = sql.NullTime{}
+		if arg.Disable {
+			feed.DisabledAt = sql.NullTime{Time: now, Valid: true}
+		}
+	})
+}
+
+func (s *Store) ReleaseFeedLease(ctx context.Context, arg database.ReleaseFeedLeaseParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.feedIndex(arg.ID)
+	if ok && s.feeds[i].ClaimedBy.Valid && s.feeds[i].ClaimedBy.String == arg.ClaimedBy {
+		s.feeds[i].ClaimedBy = sql.NullString{}
+		s.feeds[i].LeaseExpiresAt = sql.NullTime{}
+	}
+	return nil
+}
+
+func (s *Store) ResetFeedFetchErrors(ctx context.Context, id uuid.UUID) error {
+	return s.updateFeed(id, func(feed *database.Feed) {
+		feed.LastError = sql.NullString{}
+		feed.ConsecutiveFailures = 0
+		feed.NextFetchAt = sql.NullTime{}
+		feed.DisabledAt = sql.NullTime{}
+	})
+}
+
+func (s *Store) SetFeedCacheValidators(ctx context.Context, arg database.SetFeedCacheValidatorsParams) error {
+	return s.updateFeed(arg.ID, func(feed *database.Feed) {
+		feed.Etag = arg.Etag
+		feed.LastModified = arg.LastModified
+	})
+}
+
+// updateFeed applies fn to the feed with the given id, if there is one, and
+// bumps its updated_at like the UPDATE queries do.
+func (s *Store) updateFeed(id uuid.UUID, fn func(*database.Feed)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i, ok := s.feedIndex(id); ok {
+		fn(&s.feeds[i])
+		s.feeds[i].UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (s *Store) feedIndex(id uuid.UUID) (int, bool) {
+	for i, feed := range s.feeds {
+		if feed.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
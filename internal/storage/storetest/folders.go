@@ -0,0 +1,128 @@
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"gator/internal/database"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateFolder(ctx context.Context, arg database.CreateFolderParams) (database.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.folderIndex(arg.ID); ok {
+		return database.Folder{}, ErrConstraint
+	}
+	if _, ok := s.userIndex(arg.UserID); !ok {
+		return database.Folder{}, ErrConstraint
+	}
+	if _, ok := s.folderByName(arg.UserID, arg.Name); ok {
+		return database.Folder{}, ErrConstraint
+	}
+	folder := database.Folder{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		Name:      arg.Name,
+	}
+	s.folders = append(s.folders, folder)
+	return folder, nil
+}
+
+func (s *Store) DeleteFolder(ctx context.Context, arg database.DeleteFolderParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.folderByName(arg.UserID, arg.Name)
+	if !ok {
+		return 0, nil
+	}
+	id := s.folders[i].ID
+	s.folders = append(s.folders[:i:i], s.folders[i+1:]...)
+	for j := range s.follows {
+		if s.follows[j].FolderID.Valid && s.follows[j].FolderID.UUID == id {
+			s.follows[j].FolderID = uuid.NullUUID{}
+		}
+	}
+	return 1, nil
+}
+
+func (s *Store) GetFolderByName(ctx context.Context, arg database.GetFolderByNameParams) (database.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.folderByName(arg.UserID, arg.Name)
+	if !ok {
+		return database.Folder{}, sql.ErrNoRows
+	}
+	return s.folders[i], nil
+}
+
+func (s *Store) GetFoldersForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFoldersForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetFoldersForUserRow
+	for _, folder := range s.folders {
+		if folder.UserID != userID {
+			continue
+		}
+		var count int64
+		for _, follow := range s.follows {
+			if follow.FolderID.Valid && follow.FolderID.UUID == folder.ID {
+				count++
+			}
+		}
+		rows = append(rows, database.GetFoldersForUserRow{
+			ID:        folder.ID,
+			CreatedAt: folder.CreatedAt,
+			UpdatedAt: folder.UpdatedAt,
+			UserID:    folder.UserID,
+			Name:      folder.Name,
+			FeedCount: count,
+		})
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		return rows[a].Name < rows[b].Name
+	})
+	return rows, nil
+}
+
+func (s *Store) RenameFolder(ctx context.Context, arg database.RenameFolderParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.folderByName(arg.UserID, arg.Name)
+	if !ok {
+		return 0, nil
+	}
+	if j, taken := s.folderByName(arg.UserID, arg.NewName); taken && j != i {
+		return 0, ErrConstraint
+	}
+	s.folders[i].Name = arg.NewName
+	s.folders[i].UpdatedAt = time.Now()
+	return 1, nil
+}
+
+func (s *Store) folderIndex(id uuid.UUID) (int, bool) {
+	for i, folder := range s.folders {
+		if folder.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (s *Store) folderByName(userID uuid.UUID, name string) (int, bool) {
+	for i, folder := range s.folders {
+		if folder.UserID == userID && folder.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) MarkAllPostsRead(ctx context.Context, arg database.MarkAllPostsReadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var marked int64
+	for _, post := range s.posts {
+		if s.followIndex(arg.UserID, nullUUID(post.FeedID)) < 0 {
+			continue
+		}
+		if arg.FeedUrl.Valid {
+			if i, _ := s.feedIndex(post.FeedID); s.feeds[i].Url != arg.FeedUrl.String {
+				continue
+			}
+		}
+		if arg.Before.Valid && !post.PublishedAt.Before(arg.Before.Time) {
+			continue
+		}
+		if s.readAt(post.ID, arg.UserID.UUID).Valid {
+			continue
+		}
+		s.reads = append(s.reads, database.PostRead{UserID: arg.UserID.UUID, PostID: post.ID, ReadAt: arg.ReadAt})
+		marked++
+	}
+	return marked, nil
+}
+
+func (s *Store) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.readAt(arg.PostID, arg.UserID).Valid {
+		return nil
+	}
+	if _, ok := s.userIndex(arg.UserID); !ok {
+		return ErrConstraint
+	}
+	if _, ok := s.postIndex(arg.PostID); !ok {
+		return ErrConstraint
+	}
+	s.reads = append(s.reads, database.PostRead{UserID: arg.UserID, PostID: arg.PostID, ReadAt: arg.ReadAt})
+	return nil
+}
+
+func (s *Store) MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	kept := s.reads[:0]
+	for _, read := range s.reads {
+		if read.UserID == arg.UserID && read.PostID == arg.PostID {
+			deleted++
+			continue
+		}
+		kept = append(kept, read)
+	}
+	s.reads = kept
+	return deleted, nil
+}
+
+// readAt reports when a user read a post. The caller holds s.mu.
+func (s *Store) readAt(postID, userID uuid.UUID) sql.NullTime {
+	for _, read := range s.reads {
+		if read.PostID == postID && read.UserID == userID {
+			return sql.NullTime{Time: read.ReadAt, Valid: true}
+		}
+	}
+	return sql.NullTime{}
+}
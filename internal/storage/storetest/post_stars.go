@@ -0,0 +1,81 @@
+package storetest
+
+import (
+	"context"
+	"gator/internal/database"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) GetStarredPostsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetStarredPostsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetStarredPostsForUserRow
+	for _, star := range s.stars {
+		if star.UserID != userID {
+			continue
+		}
+		i, _ := s.postIndex(star.PostID)
+		post := s.posts[i]
+		j, _ := s.feedIndex(post.FeedID)
+		rows = append(rows, database.GetStarredPostsForUserRow{
+			ID:                 post.ID,
+			CreatedAt:          post.CreatedAt,
+			UpdatedAt:          post.UpdatedAt,
+			Title:              post.Title,
+			Url:                post.Url,
+			Description:        post.Description,
+			PublishedAt:        post.PublishedAt,
+			FeedID:             post.FeedID,
+			PublishedAtUnknown: post.PublishedAtUnknown,
+			Author:             post.Author,
+			FeedName:           s.feedTitle(nullUUID(userID), s.feeds[j]),
+			StarredAt:          star.StarredAt,
+		})
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		return rows[a].StarredAt.After(rows[b].StarredAt)
+	})
+	return rows, nil
+}
+
+func (s *Store) StarPost(ctx context.Context, arg database.StarPostParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, star := range s.stars {
+		if star.UserID == arg.UserID && star.PostID == arg.PostID {
+			return nil
+		}
+	}
+	if _, ok := s.userIndex(arg.UserID); !ok {
+		return ErrConstraint
+	}
+	if _, ok := s.postIndex(arg.PostID); !ok {
+		return ErrConstraint
+	}
+	s.stars = append(s.stars, database.PostStar{UserID: arg.UserID, PostID: arg.PostID, StarredAt: arg.StarredAt})
+	return nil
+}
+
+func (s *Store) UnstarPost(ctx context.Context, arg database.UnstarPostParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	kept := s.stars[:0]
+	for _, star := range s.stars {
+		if star.UserID == arg.UserID {
+			i, _ := s.postIndex(star.PostID)
+			if post := s.posts[i]; post.ID.String() == arg.Post || post.Url == arg.Post {
+				deleted++
+				continue
+			}
+		}
+		kept = append(kept, star)
+	}
+	s.stars = kept
+	return deleted, nil
+}
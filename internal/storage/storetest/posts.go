@@ -0,0 +1,213 @@
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"gator/internal/database"
+	"gator/internal/storage"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.CreatePostRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		post := &s.posts[i]
+		if post.FeedID != arg.FeedID || post.Url != arg.Url {
+			continue
+		}
+		post.UpdatedAt = arg.UpdatedAt
+		post.Title = arg.Title
+		post.Description = arg.Description
+		post.Author = arg.Author
+		if !arg.PublishedAtUnknown {
+			post.PublishedAt = arg.PublishedAt
+		}
+		post.PublishedAtUnknown = post.PublishedAtUnknown && arg.PublishedAtUnknown
+		return database.CreatePostRow(newPostRow(*post)), nil
+	}
+
+	if _, ok := s.feedIndex(arg.FeedID); !ok {
+		return database.CreatePostRow{}, ErrConstraint
+	}
+	for _, post := range s.posts {
+		if post.ID == arg.ID {
+			return database.CreatePostRow{}, ErrConstraint
+		}
+	}
+	post := database.Post{
+		ID:                 arg.ID,
+		CreatedAt:          arg.CreatedAt,
+		UpdatedAt:          arg.UpdatedAt,
+		Title:              arg.Title,
+		Url:                arg.Url,
+		Description:        arg.Description,
+		PublishedAt:        arg.PublishedAt,
+		FeedID:             arg.FeedID,
+		PublishedAtUnknown: arg.PublishedAtUnknown,
+		Author:             arg.Author,
+	}
+	s.posts = append(s.posts, post)
+	return database.CreatePostRow(newPostRow(post)), nil
+}
+
+func (s *Store) GetPostForUser(ctx context.Context, arg database.GetPostForUserParams) (database.GetPostForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *database.Post
+	for i, post := range s.posts {
+		if post.ID.String() != arg.Post && post.Url != arg.Post {
+			continue
+		}
+		if s.followIndex(arg.UserID, nullUUID(post.FeedID)) < 0 {
+			continue
+		}
+		if found == nil || post.PublishedAt.After(found.PublishedAt) {
+			found = &s.posts[i]
+		}
+	}
+	if found == nil {
+		return database.GetPostForUserRow{}, sql.ErrNoRows
+	}
+	return database.GetPostForUserRow(newPostRow(*found)), nil
+}
+
+func (s *Store) GetPostsForUser(ctx context.Context, arg database.GetPostsForUserParams) ([]database.GetPostsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetPostsForUserRow
+	for _, post := range s.posts {
+		f := s.followIndex(arg.UserID, nullUUID(post.FeedID))
+		if f < 0 {
+			continue
+		}
+		if arg.FolderID.Valid && s.follows[f].FolderID != arg.FolderID {
+			continue
+		}
+		readAt := s.readAt(post.ID, arg.UserID.UUID)
+		if readAt.Valid && !arg.IncludeRead {
+			continue
+		}
+		i, _ := s.feedIndex(post.FeedID)
+		rows = append(rows, database.GetPostsForUserRow{
+			ID:                 post.ID,
+			CreatedAt:          post.CreatedAt,
+			UpdatedAt:          post.UpdatedAt,
+			Title:              post.Title,
+			Url:                post.Url,
+			Description:        post.Description,
+			PublishedAt:        post.PublishedAt,
+			FeedID:             post.FeedID,
+			PublishedAtUnknown: post.PublishedAtUnknown,
+			Author:             post.Author,
+			FeedName:           s.feedTitle(arg.UserID, s.feeds[i]),
+			ReadAt:             readAt,
+		})
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		return rows[a].PublishedAt.After(rows[b].PublishedAt)
+	})
+	if len(rows) > int(arg.Limit) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
+
+// postRow has the columns the queries select from posts, which leave out
+// search_vector. It converts to each query's row type.
+type postRow struct {
+	ID                 uuid.UUID
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Title              string
+	Url                string
+	Description        sql.NullString
+	PublishedAt        time.Time
+	FeedID             uuid.UUID
+	PublishedAtUnknown bool
+	Author             sql.NullString
+}
+
+func newPostRow(post database.Post) postRow {
+	return postRow{
+		ID:                 post.ID,
+		CreatedAt:          post.CreatedAt,
+		UpdatedAt:          post.UpdatedAt,
+		Title:              post.Title,
+		Url:                post.Url,
+		Description:        post.Description,
+		PublishedAt:        post.PublishedAt,
+		FeedID:             post.FeedID,
+		PublishedAtUnknown: post.PublishedAtUnknown,
+		Author:             post.Author,
+	}
+}
+
+func (s *Store) postIndex(id uuid.UUID) (int, bool) {
+	for i, post := range s.posts {
+		if post.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// SearchPosts matches and ranks posts like the SQLite backend does, best
+// match first.
+func (s *Store) SearchPosts(ctx context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := storage.ParseSearchQuery(arg.Query)
+	if query.Empty() {
+		return nil, nil
+	}
+	var rows []database.SearchPostsRow
+	for _, post := range s.posts {
+		if s.followIndex(arg.UserID, nullUUID(post.FeedID)) < 0 {
+			continue
+		}
+		i, _ := s.feedIndex(post.FeedID)
+		feed := s.feeds[i]
+		if arg.FeedUrl.Valid && feed.Url != arg.FeedUrl.String {
+			continue
+		}
+		if arg.Since.Valid && post.PublishedAt.Before(arg.Since.Time) {
+			continue
+		}
+		rank := query.Rank(post.Title, post.Description.String)
+		if rank == 0 {
+			continue
+		}
+		body := post.Title
+		if post.Description.Valid {
+			body = post.Description.String
+		}
+		rows = append(rows, database.SearchPostsRow{
+			ID:                 post.ID,
+			Title:              post.Title,
+			Url:                post.Url,
+			PublishedAt:        post.PublishedAt,
+			PublishedAtUnknown: post.PublishedAtUnknown,
+			FeedName:           s.feedTitle(arg.UserID, feed),
+			Rank:               rank,
+			Snippet:            query.Snippet(body),
+		})
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		if rows[a].Rank != rows[b].Rank {
+			return rows[a].Rank > rows[b].Rank
+		}
+		return rows[a].PublishedAt.After(rows[b].PublishedAt)
+	})
+	if len(rows) > int(arg.Limit) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
@@ -0,0 +1,90 @@
+// Package storetest provides an in-memory storage.Store for unit tests of
+// code that would otherwise need a live database. It mimics the constraints
+// the real schema enforces (unique keys, foreign keys, cascading deletes) and
+// returns sql.ErrNoRows where a query would find nothing.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"gator/internal/database"
+	"gator/internal/storage"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrConstraint is returned when a write would break a unique or foreign key
+// constraint of the real schema.
+var ErrConstraint = errors.New("constraint violation")
+
+// Store keeps every table in memory. The zero value is not usable; call New.
+type Store struct {
+	mu      sync.Mutex
+	users   []database.User
+	feeds   []database.Feed
+	follows []database.FeedFollow
+	folders []database.Folder
+	posts   []database.Post
+	reads   []database.PostRead
+	stars   []database.PostStar
+}
+
+var _ storage.Store = (*Store)(nil)
+
+func New() *Store {
+	return &Store{}
+}
+
+// InTx runs fn against the store itself and restores the previous contents
+// if it fails. Unlike a real transaction it does not isolate fn from
+// concurrent callers.
+func (s *Store) InTx(ctx context.Context, fn func(database.Querier) error) error {
+	s.mu.Lock()
+	saved := s.snapshot()
+	s.mu.Unlock()
+
+	if err := fn(s); err != nil {
+		s.mu.Lock()
+		s.restore(saved)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+type tables struct {
+	users   []database.User
+	feeds   []database.Feed
+	follows []database.FeedFollow
+	folders []database.Folder
+	posts   []database.Post
+	reads   []database.PostRead
+	stars   []database.PostStar
+}
+
+func (s *Store) snapshot() tables {
+	return tables{
+		users:   append([]database.User(nil), s.users...),
+		feeds:   append([]database.Feed(nil), s.feeds...),
+		follows: append([]database.FeedFollow(nil), s.follows...),
+		folders: append([]database.Folder(nil), s.folders...),
+		posts:   append([]database.Post(nil), s.posts...),
+		reads:   append([]database.PostRead(nil), s.reads...),
+		stars:   append([]database.PostStar(nil), s.stars...),
+	}
+}
+
+func (s *Store) restore(t tables) {
+	s.users = t.users
+	s.feeds = t.feeds
+	s.follows = t.follows
+	s.folders = t.folders
+	s.posts = t.posts
+	s.reads = t.reads
+	s.stars = t.stars
+}
+
+func nullUUID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: true}
+}
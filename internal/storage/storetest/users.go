@@ -0,0 +1,85 @@
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.userIndex(arg.ID); ok {
+		return database.User{}, ErrConstraint
+	}
+	user := database.User{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+	}
+	s.users = append(s.users, user)
+	return user, nil
+}
+
+// DeleteUser deletes every user and, through the cascades, everything else.
+func (s *Store) DeleteUser(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = nil
+	s.feeds = nil
+	s.follows = nil
+	s.folders = nil
+	s.posts = nil
+	s.reads = nil
+	s.stars = nil
+	return nil
+}
+
+func (s *Store) GetUser(ctx context.Context, name sql.NullString) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if name.Valid && user.Name == name {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.userIndex(id)
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+	return s.users[i], nil
+}
+
+func (s *Store) GetUserIDByName(ctx context.Context, name sql.NullString) (uuid.UUID, error) {
+	user, err := s.GetUser(ctx, name)
+	return user.ID, err
+}
+
+func (s *Store) GetUsers(ctx context.Context) ([]database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]database.User(nil), s.users...), nil
+}
+
+func (s *Store) userIndex(id uuid.UUID) (int, bool) {
+	for i, user := range s.users {
+		if user.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+package utils
+
+import "strings"
+
+type atomFeed struct {
+	Title    atomText    `xml:"title"`
+	Subtitle atomText    `xml:"subtitle"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// atomText is an Atom text construct. Plain and escaped html content arrive
+// as character data, xhtml content as a nested <div>.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Body  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Body)
+}
+
+func parseAtom(body []byte) (*ParsedFeed, error) {
+	var resFeed atomFeed
+	if err := unmarshalXML(body, &resFeed); err != nil {
+		return nil, err
+	}
+
+	feed := &ParsedFeed{
+		Title:       resFeed.Title.String(),
+		Link:        alternateLink(resFeed.Links),
+		Description: resFeed.Subtitle.String(),
+	}
+	for _, entry := range resFeed.Entries {
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+		feed.Items = append(feed.Items, FeedItem{
+			ID:          strings.TrimSpace(entry.ID),
+			Title:       entry.Title.String(),
+			Link:        alternateLink(entry.Links),
+			Description: description,
+			PubDate:     strings.TrimSpace(pubDate),
+		})
+	}
+	return feed, nil
+}
+
+// alternateLink returns the href of the rel="alternate" link, which is the
+// default when rel is omitted, falling back to the first link present.
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package utils
+
+import "testing"
+
+func TestParseAtom(t *testing.T) {
+	feed, err := ParseFeed([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="html">Tom &amp;amp; Jerry</title>
+  <subtitle>Cartoons</subtitle>
+  <link rel="self" href="https://blog.example/feed.atom"/>
+  <link href="https://blog.example/"/>
+</feed>`), "application/atom+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Tom & Jerry" || feed.Description != "Cartoons" || feed.Link != "https://blog.example/" {
+		t.Errorf("got title %q, description %q, link %q", feed.Title, feed.Description, feed.Link)
+	}
+}
+
+func TestParseAtomEntry(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry string
+		want  FeedItem
+	}{
+		{
+			"text",
+			`<id> urn:1 </id><title> Hello </title><link href="https://blog.example/1"/><summary>Plain</summary>`,
+			FeedItem{ID: "urn:1", Title: "Hello", Link: "https://blog.example/1", Description: "Plain"},
+		},
+		{
+			"escaped html",
+			`<summary type="html">&lt;p&gt;Hi &amp;amp; bye&lt;/p&gt;</summary>`,
+			FeedItem{Description: "<p>Hi & bye</p>"},
+		},
+		{
+			"xhtml",
+			`<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hi <b>there</b></p></div></content>`,
+			FeedItem{Description: `<div xmlns="http://www.w3.org/1999/xhtml"><p>Hi <b>there</b></p></div>`},
+		},
+		{
+			"xhtml title",
+			`<title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">A <i>B</i></div></title>`,
+			FeedItem{Title: `<div xmlns="http://www.w3.org/1999/xhtml">A <i>B</i></div>`},
+		},
+		{
+			"summary before content",
+			`<summary>Short</summary><content type="html">&lt;p&gt;Long&lt;/p&gt;</content>`,
+			FeedItem{Description: "Short"},
+		},
+		{
+			"content without summary",
+			`<content type="html">&lt;p&gt;Long&lt;/p&gt;</content>`,
+			FeedItem{Description: "<p>Long</p>"},
+		},
+		{
+			"alternate after self",
+			`<link rel="self" href="https://blog.example/1.atom"/><link rel="alternate" href="https://blog.example/1"/>`,
+			FeedItem{Link: "https://blog.example/1"},
+		},
+		{
+			"rel omitted",
+			`<link rel="enclosure" href="https://blog.example/1.mp3"/><link href="https://blog.example/1"/>`,
+			FeedItem{Link: "https://blog.example/1"},
+		},
+		{
+			"no alternate",
+			`<link rel="related" href="https://other.example/"/><link rel="self" href="https://blog.example/1.atom"/>`,
+			FeedItem{Link: "https://other.example/"},
+		},
+		{
+			"published",
+			`<published>2006-01-02T15:04:05Z</published><updated>2007-01-02T15:04:05Z</updated>`,
+			FeedItem{PubDate: "2006-01-02T15:04:05Z"},
+		},
+		{
+			"updated without published",
+			`<updated> 2007-01-02T15:04:05Z </updated>`,
+			FeedItem{PubDate: "2007-01-02T15:04:05Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `<feed xmlns="http://www.w3.org/2005/Atom"><entry>` + tt.entry + `</entry></feed>`
+			feed, err := ParseFeed([]byte(body), "application/atom+xml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(feed.Items) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Items))
+			}
+			if got := feed.Items[0]; got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// windows1252High maps bytes 0x80-0x9F of windows-1252 to Unicode. The rest
+// of the code page coincides with ISO-8859-1 and therefore with Unicode.
+var windows1252High = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// iso885915Overrides lists the code points where ISO-8859-15 departs from
+// ISO-8859-1.
+var iso885915Overrides = map[byte]rune{
+	0xA4: 0x20AC,
+	0xA6: 0x0160,
+	0xA8: 0x0161,
+	0xB4: 0x017D,
+	0xB8: 0x017E,
+	0xBC: 0x0152,
+	0xBD: 0x0153,
+	0xBE: 0x0178,
+}
+
+func windows1252Rune(b byte) rune {
+	if b >= 0x80 && b <= 0x9F {
+		return windows1252High[b-0x80]
+	}
+	return rune(b)
+}
+
+func iso885915Rune(b byte) rune {
+	if r, ok := iso885915Overrides[b]; ok {
+		return r
+	}
+	return rune(b)
+}
+
+// charsetReader is an xml.Decoder.CharsetReader for the single-byte legacy
+// encodings feeds still declare. Following the WHATWG encoding standard,
+// ISO-8859-1 and US-ASCII are decoded as windows-1252, which is what servers
+// labelling their feeds that way almost always send.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	var decode func(byte) rune
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "utf-8", "utf8":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1", "us-ascii", "ascii",
+		"windows-1252", "cp1252", "x-cp1252":
+		decode = windows1252Rune
+	case "iso-8859-15", "iso8859-15", "iso_8859-15", "latin9", "l9":
+		decode = iso885915Rune
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", label)
+	}
+
+	raw, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	decoded := make([]byte, 0, len(raw)+len(raw)/4)
+	for _, b := range raw {
+		decoded = utf8.AppendRune(decoded, decode(b))
+	}
+	return bytes.NewReader(decoded), nil
+}
+
+func newXMLDecoder(body []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// unmarshalXML is xml.Unmarshal with legacy charset support.
+func unmarshalXML(body []byte, v any) error {
+	return newXMLDecoder(body).Decode(v)
+}
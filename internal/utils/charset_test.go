@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCharsetReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		label string
+		input string
+		want  string
+	}{
+		{"utf-8 untouched", "UTF-8", "caf\xc3\xa9", "café"},
+		{"latin1 letters", "ISO-8859-1", "caf\xe9 \xfc\xdf", "café üß"},
+		{"windows-1252 euro", "windows-1252", "\x80", "€"},
+		{"windows-1252 quotes", "cp1252", "\x93quoted\x94 it\x92s", "“quoted” it’s"},
+		{"windows-1252 dashes", "windows-1252", "a\x96b\x97c\x85", "a–b—c…"},
+		{"windows-1252 0x8A-0x9F", "windows-1252", "\x8a\x8c\x8e\x99\x9a\x9c\x9e\x9f", "ŠŒŽ™šœžŸ"},
+		{"windows-1252 undefined bytes", "windows-1252", "\x81\x8d\x8f\x90\x9d", "\u0081\u008d\u008f\u0090\u009d"},
+		{"latin1 label decodes as windows-1252", "latin1", "\x80 \x9f", "€ Ÿ"},
+		{"us-ascii label decodes as windows-1252", "us-ascii", "\x93hi\x94", "“hi”"},
+		{"iso-8859-15 overrides", "ISO-8859-15", "\xa4\xa6\xa8\xb4\xb8\xbc\xbd\xbe", "€ŠšŽžŒœŸ"},
+		{"iso-8859-15 shared", "latin9", "\xe9\xa3\xa7", "é£§"},
+		{"iso-8859-15 C1 controls", "iso-8859-15", "\x80", "\u0080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := charsetReader(tt.label, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCharsetReaderUnsupported(t *testing.T) {
+	if _, err := charsetReader("shift_jis", strings.NewReader("")); err == nil {
+		t.Error("want an error for an unsupported charset")
+	}
+}
+
+func TestParseFeedCharset(t *testing.T) {
+	body := "<?xml version=\"1.0\" encoding=\"windows-1252\"?>\n" +
+		"<rss version=\"2.0\"><channel><title>Caf\xe9 \x93News\x94</title></channel></rss>"
+	feed, err := ParseFeed([]byte(body), "application/rss+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Café “News”" {
+		t.Errorf("title = %q", feed.Title)
+	}
+}
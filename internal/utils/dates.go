@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dateLayouts are tried in order after a date string has been normalised by
+// normaliseDate: weekday removed, named zone replaced by a numeric offset.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2-Jan-06 15:04:05 -0700",
+	"2-Jan-2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2 January 2006",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 2006 15:04:05",
+	"January 2 2006 15:04:05 -0700",
+	"Jan 2 2006",
+	"January 2 2006",
+	// ANSI C and UnixDate, as printed by date(1), and RubyDate.
+	"Jan 2 15:04:05 2006",
+	"Jan 2 15:04:05 -0700 2006",
+}
+
+// zoneOffsets maps the timezone abbreviations seen in feeds to numeric
+// offsets. time.Parse only resolves abbreviations of the local zone and
+// silently treats every other one as UTC.
+var zoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+	"BST":  "+0100",
+	"WET":  "+0000",
+	"WEST": "+0100",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"MSK":  "+0300",
+	"IST":  "+0530",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+}
+
+var (
+	weekdayPrefix  = regexp.MustCompile(`^(?i)(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?(,\s*|\s+)`)
+	zoneComment    = regexp.MustCompile(`\s*\([^)]*\)$`)
+	trailingZone   = regexp.MustCompile(`\s+([A-Za-z]{1,5})$`)
+	colonOffset    = regexp.MustCompile(`\s([+-]\d{2}):(\d{2})$`)
+	gmtOffset      = regexp.MustCompile(`\s(?:GMT|UTC)([+-]\d{4})$`)
+	zoneBeforeYear = regexp.MustCompile(`\s([A-Za-z]{1,5})\s(\d{4})$`)
+	septMonth      = regexp.MustCompile(`(?i)\bsept\b\.?`)
+	commaOrSpaces  = regexp.MustCompile(`[\s,]+`)
+	isoLikeWithSep = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+)
+
+// ParseDate parses a publication date in any of the formats commonly found in
+// RSS, Atom and JSON feeds. The result is in UTC; ok is false when the value
+// could not be understood.
+func ParseDate(value string) (t time.Time, ok bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	normalised := normaliseDate(value)
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, normalised); err == nil {
+			return parsed.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func normaliseDate(value string) string {
+	if isoLikeWithSep.MatchString(value) {
+		// RFC 3339 and friends only need their zone cleaned up.
+		if match := trailingZone.FindStringSubmatch(value); match != nil {
+			if offset, ok := zoneOffsets[strings.ToUpper(match[1])]; ok {
+				value = strings.TrimSuffix(value, match[0]) + " " + offset
+			}
+		}
+		return value
+	}
+
+	value = zoneComment.ReplaceAllString(value, "")
+	value = weekdayPrefix.ReplaceAllString(value, "")
+	value = septMonth.ReplaceAllString(value, "Sep")
+	value = commaOrSpaces.ReplaceAllString(value, " ")
+	value = strings.TrimSpace(value)
+
+	if match := gmtOffset.FindStringSubmatch(value); match != nil {
+		value = strings.TrimSuffix(value, match[0]) + " " + match[1]
+	}
+	if match := colonOffset.FindStringSubmatch(value); match != nil {
+		value = strings.TrimSuffix(value, match[0]) + fmt.Sprintf(" %s%s", match[1], match[2])
+	}
+	if match := trailingZone.FindStringSubmatch(value); match != nil {
+		if offset, ok := zoneOffsets[strings.ToUpper(match[1])]; ok {
+			value = strings.TrimSuffix(value, match[0]) + " " + offset
+		}
+	}
+	if match := zoneBeforeYear.FindStringSubmatch(value); match != nil {
+		if offset, ok := zoneOffsets[strings.ToUpper(match[1])]; ok {
+			value = strings.TrimSuffix(value, match[0]) + " " + offset + " " + match[2]
+		}
+	}
+	return value
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"RFC1123", "Mon, 02 Jan 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"RFC1123Z", "Mon, 02 Jan 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"RFC822", "02 Jan 06 15:04 MST", "2006-01-02T22:04:00Z"},
+		{"RFC822Z", "02 Jan 06 15:04 -0700", "2006-01-02T22:04:00Z"},
+		{"RFC822 with seconds", "Mon, 02 Jan 06 15:04:05 +0100", "2006-01-02T14:04:05Z"},
+		{"RFC3339", "2006-01-02T15:04:05Z", "2006-01-02T15:04:05Z"},
+		{"RFC3339 offset", "2006-01-02T15:04:05+02:00", "2006-01-02T13:04:05Z"},
+		{"RFC3339 fractional", "2006-01-02T15:04:05.123456Z", "2006-01-02T15:04:05.123456Z"},
+		{"RFC3339 no seconds", "2006-01-02T15:04+02:00", "2006-01-02T13:04:00Z"},
+		{"ISO without zone", "2006-01-02T15:04:05", "2006-01-02T15:04:05Z"},
+		{"ISO with space", "2006-01-02 15:04:05", "2006-01-02T15:04:05Z"},
+		{"ISO with named zone", "2006-01-02 15:04:05 PDT", "2006-01-02T22:04:05Z"},
+		{"date only", "2006-01-02", "2006-01-02T00:00:00Z"},
+		{"EST", "Mon, 02 Jan 2006 15:04:05 EST", "2006-01-02T20:04:05Z"},
+		{"PDT", "Mon, 02 Jan 2006 15:04:05 PDT", "2006-01-02T22:04:05Z"},
+		{"lowercase zone", "Mon, 02 Jan 2006 15:04:05 pdt", "2006-01-02T22:04:05Z"},
+		{"missing weekday", "02 Jan 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"long weekday", "Monday, 02 Jan 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"wrong weekday", "Tue, 02 Jan 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"single digit day", "Mon, 2 Jan 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"no comma", "Mon 02 Jan 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"extra spaces", "  Mon,  02  Jan 2006   15:04:05 GMT ", "2006-01-02T15:04:05Z"},
+		{"colon offset", "Mon, 02 Jan 2006 15:04:05 +02:00", "2006-01-02T13:04:05Z"},
+		{"GMT offset", "Mon, 02 Jan 2006 15:04:05 GMT+0200", "2006-01-02T13:04:05Z"},
+		{"zone comment", "Mon, 02 Jan 2006 15:04:05 +0000 (UTC)", "2006-01-02T15:04:05Z"},
+		{"no seconds", "Mon, 02 Jan 2006 15:04 GMT", "2006-01-02T15:04:00Z"},
+		{"no zone", "Mon, 02 Jan 2006 15:04:05", "2006-01-02T15:04:05Z"},
+		{"dashed", "02-Jan-2006 15:04:05 +0000", "2006-01-02T15:04:05Z"},
+		{"long month", "2 January 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"long month date only", "2 January 2006", "2006-01-02T00:00:00Z"},
+		{"month first", "Jan 2, 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"month first date only", "January 2, 2006", "2006-01-02T00:00:00Z"},
+		{"Sept", "Sept 5 2024", "2024-09-05T00:00:00Z"},
+		{"Sept with dot", "Sept. 5, 2024", "2024-09-05T00:00:00Z"},
+		{"ANSI C", "Mon Jan  2 15:04:05 2006", "2006-01-02T15:04:05Z"},
+		{"UnixDate", "Mon Jan  2 15:04:05 MST 2006", "2006-01-02T22:04:05Z"},
+		{"RubyDate", "Mon Jan 02 15:04:05 -0700 2006", "2006-01-02T22:04:05Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseDate(tt.value)
+			if !ok {
+				t.Fatalf("ParseDate(%q) failed", tt.value)
+			}
+			want, err := time.Parse(time.RFC3339Nano, tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) || got.Location() != time.UTC {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.value, got, want)
+			}
+		})
+	}
+}
+
+func TestParseDateUnknown(t *testing.T) {
+	for _, value := range []string{"", "   ", "yesterday", "not a date", "32 Jan 2006", "Mon, 02 Foo 2006 15:04:05 GMT"} {
+		if got, ok := ParseDate(value); ok {
+			t.Errorf("ParseDate(%q) = %v, want failure", value, got)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DiscoveredFeed is a feed found by DiscoverFeeds. Feed is set when the
+// candidate was fetched and parsed, during discovery or by Resolve.
+type DiscoveredFeed struct {
+	URL   string
+	Title string
+	Type  string
+	Feed  *ParsedFeed
+}
+
+// feedLinkTypes are the <link rel="alternate"> types that point at feeds.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/rdf+xml":   true,
+}
+
+// commonFeedPaths are probed when a page advertises no feed.
+var commonFeedPaths = []string{
+	"/feed",
+	"/rss.xml",
+	"/index.xml",
+	"/atom.xml",
+	"/feed.xml",
+	"/rss",
+	"/feed.json",
+}
+
+var (
+	htmlLinkTag   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	htmlBaseTag   = regexp.MustCompile(`(?is)<base\b[^>]*>`)
+	htmlAttribute = regexp.MustCompile(`(?s)([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// DiscoverFeeds finds the feeds behind pageURL. If pageURL already is a
+// feed, it is the only result. Otherwise the page's <link rel="alternate">
+// tags are used and, failing those, a few well-known feed paths are probed.
+// Returned URLs are absolute. Those of fetched candidates reflect any
+// redirects followed; linked ones are as the page gives them until Resolve is
+// called. Finding no feed at all is an error. Every response is capped at maxBytes, or
+// DefaultMaxBodyBytes when it is not positive.
+func DiscoverFeeds(ctx context.Context, pageURL string, maxBytes int64) ([]DiscoveredFeed, error) {
+	body, finalURL, contentType, err := fetchPage(ctx, pageURL, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	feed, parseErr := ParseFeed(body, contentType)
+	if parseErr == nil {
+		return []DiscoveredFeed{{URL: finalURL.String(), Title: feed.Title, Feed: feed}}, nil
+	}
+
+	if found := feedLinks(body, finalURL); len(found) > 0 {
+		return found, nil
+	}
+
+	var found []DiscoveredFeed
+	for _, path := range commonFeedPaths {
+		candidate := finalURL.ResolveReference(&url.URL{Path: path})
+		body, probedURL, contentType, err := fetchPage(ctx, candidate.String(), maxBytes)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		feed, err := ParseFeed(body, contentType)
+		if err != nil || containsFeedURL(found, probedURL.String()) {
+			continue
+		}
+		found = append(found, DiscoveredFeed{URL: probedURL.String(), Title: feed.Title, Feed: feed})
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("not a feed (%v) and no feed is linked from the page", parseErr)
+	}
+	return found, nil
+}
+
+// Resolve fetches and parses a candidate that discovery did not fetch, and
+// points URL at wherever redirects led, so the canonical feed URL is kept.
+func (d *DiscoveredFeed) Resolve(ctx context.Context, maxBytes int64) error {
+	if d.Feed != nil {
+		return nil
+	}
+	body, finalURL, contentType, err := fetchPage(ctx, d.URL, maxBytes)
+	if err != nil {
+		return err
+	}
+	feed, err := ParseFeed(body, contentType)
+	if err != nil {
+		return err
+	}
+	d.URL = finalURL.String()
+	d.Feed = feed
+	return nil
+}
+
+func fetchPage(ctx context.Context, pageURL string, maxBytes int64) ([]byte, *url.URL, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, "", newHTTPStatusError(res)
+	}
+
+	body, err := readBody(res, maxBytes)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return body, res.Request.URL, res.Header.Get("Content-Type"), nil
+}
+
+// feedLinks extracts feed links from an HTML page, resolving relative hrefs
+// against the page URL or its <base href>.
+func feedLinks(body []byte, pageURL *url.URL) []DiscoveredFeed {
+	base := pageURL
+	if tag := htmlBaseTag.Find(body); tag != nil {
+		if href := htmlAttributes(tag)["href"]; href != "" {
+			if parsed, err := pageURL.Parse(href); err == nil {
+				base = parsed
+			}
+		}
+	}
+
+	var found []DiscoveredFeed
+	for _, tag := range htmlLinkTag.FindAll(body, -1) {
+		attrs := htmlAttributes(tag)
+		if !hasToken(attrs["rel"], "alternate") {
+			continue
+		}
+		linkType := strings.ToLower(strings.TrimSpace(attrs["type"]))
+		if !feedLinkTypes[linkType] || attrs["href"] == "" {
+			continue
+		}
+		resolved, err := base.Parse(attrs["href"])
+		if err != nil || containsFeedURL(found, resolved.String()) {
+			continue
+		}
+		found = append(found, DiscoveredFeed{
+			URL:   resolved.String(),
+			Title: attrs["title"],
+			Type:  linkType,
+		})
+	}
+	return found
+}
+
+func htmlAttributes(tag []byte) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range htmlAttribute.FindAllSubmatch(tag, -1) {
+		name := strings.ToLower(string(match[1]))
+		value := string(match[2]) + string(match[3]) + string(match[4])
+		if _, seen := attrs[name]; !seen {
+			attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return attrs
+}
+
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(list) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFeedURL(feeds []DiscoveredFeed, feedURL string) bool {
+	for _, feed := range feeds {
+		if feed.URL == feedURL {
+			return true
+		}
+	}
+	return false
+}
+
+func (d DiscoveredFeed) String() string {
+	if d.Title == "" {
+		return d.URL
+	}
+	return fmt.Sprintf("%s (%s)", d.Title, d.URL)
+}
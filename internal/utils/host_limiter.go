@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const userAgent = "gator"
+
+// HostLimiter keeps the scraper polite towards hosts that serve several
+// feeds: it caps how many requests run against one host at a time and spaces
+// out their start times. With robots enabled, a host's robots.txt
+// Crawl-delay is honoured when it is longer than the configured delay.
+type HostLimiter struct {
+	delay          time.Duration
+	maxConcurrency int
+	robots         bool
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	slots chan struct{}
+	next  time.Time
+
+	robotsOnce sync.Once
+	crawlDelay time.Duration
+}
+
+func NewHostLimiter(delay time.Duration, maxConcurrency int, robots bool) *HostLimiter {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	return &HostLimiter{
+		delay:          delay,
+		maxConcurrency: maxConcurrency,
+		robots:         robots,
+		hosts:          make(map[string]*hostState),
+	}
+}
+
+// ErrHostBusy is returned by TryAcquire when every slot for the host is taken.
+var ErrHostBusy = errors.New("host is busy")
+
+// TryAcquire takes a slot for a request to feedURL's host without queueing
+// behind other requests, failing with ErrHostBusy if none is free. Once it has
+// a slot it waits out the host's spacing delay; that wait fails at once if the
+// host's next turn is past ctx's deadline, or with ctx's error if ctx ends
+// first. The returned release func must be called once the request is done.
+func (l *HostLimiter) TryAcquire(ctx context.Context, feedURL string) (release func(), err error) {
+	parsed, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	host := strings.ToLower(parsed.Host)
+	state := l.host(host)
+
+	select {
+	case state.slots <- struct{}{}:
+	default:
+		return nil, ErrHostBusy
+	}
+	release = func() { <-state.slots }
+
+	if l.robots {
+		state.robotsOnce.Do(func() {
+			state.crawlDelay = fetchCrawlDelay(ctx, parsed.Scheme+"://"+parsed.Host)
+		})
+	}
+
+	delay := l.delay
+	if state.crawlDelay > delay {
+		delay = state.crawlDelay
+	}
+
+	l.mu.Lock()
+	start := time.Now()
+	if state.next.After(start) {
+		start = state.next
+	}
+	// Don't hold a turn the caller cannot wait for; it would only push back
+	// every later request to the host.
+	if deadline, ok := ctx.Deadline(); ok && start.After(deadline) {
+		l.mu.Unlock()
+		release()
+		return nil, context.DeadlineExceeded
+	}
+	state.next = start.Add(delay)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(start))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+func (l *HostLimiter) host(host string) *hostState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state, ok := l.hosts[host]
+	if !ok {
+		state = &hostState{slots: make(chan struct{}, l.maxConcurrency)}
+		l.hosts[host] = state
+	}
+	return state
+}
+
+// fetchCrawlDelay returns the Crawl-delay robots.txt at origin sets for
+// gator, falling back to the wildcard group. Any failure means no delay.
+func fetchCrawlDelay(ctx context.Context, origin string) time.Duration {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", origin+"/robots.txt", nil)
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("User-Agent", userAgent)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0
+	}
+
+	delay, err := parseCrawlDelay(io.LimitReader(res.Body, 512*1024), userAgent)
+	if err != nil {
+		return 0
+	}
+	return delay
+}
+
+func parseCrawlDelay(r io.Reader, agent string) (time.Duration, error) {
+	var (
+		specific, wildcard time.Duration
+		foundSpecific      bool
+		groupAgents        []string
+		inRules            bool
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group.
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+		case "crawl-delay":
+			inRules = true
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			delay := time.Duration(seconds * float64(time.Second))
+			for _, groupAgent := range groupAgents {
+				if groupAgent == "*" {
+					wildcard = delay
+				} else if strings.EqualFold(groupAgent, agent) {
+					specific = delay
+					foundSpecific = true
+				}
+			}
+		default:
+			inRules = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read robots.txt: %v", err)
+	}
+	if foundSpecific {
+		return specific, nil
+	}
+	return wildcard, nil
+}
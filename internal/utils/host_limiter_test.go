@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCrawlDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		robots string
+		want   time.Duration
+	}{
+		{"none", "User-agent: *\nDisallow: /private\n", 0},
+		{"wildcard", "User-agent: *\nCrawl-delay: 5\n", 5 * time.Second},
+		{"own group wins", "User-agent: *\nCrawl-delay: 5\n\nUser-agent: gator\nCrawl-delay: 2\n", 2 * time.Second},
+		{"case insensitive", "User-agent: Gator\nCrawl-delay: 2\n", 2 * time.Second},
+		{"shared group", "User-agent: otherbot\nUser-agent: gator\nCrawl-delay: 1.5\n", 1500 * time.Millisecond},
+		{"substring agent", "User-agent: a\nCrawl-delay: 30\n\nUser-agent: *\nCrawl-delay: 1\n", time.Second},
+		{"longer agent", "User-agent: gatorbot\nCrawl-delay: 30\n", 0},
+		{"other agent", "User-agent: googlebot\nCrawl-delay: 30\n", 0},
+		{"invalid delay", "User-agent: *\nCrawl-delay: soon\n", 0},
+		{"comments", "User-agent: * # everyone\nCrawl-delay: 3 # seconds\n", 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCrawlDelay(strings.NewReader(tt.robots), userAgent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTryAcquire(t *testing.T) {
+	l := NewHostLimiter(0, 1, false)
+	ctx := context.Background()
+
+	release, err := l.TryAcquire(ctx, "https://blog.example/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.TryAcquire(ctx, "https://BLOG.example/other.xml"); !errors.Is(err, ErrHostBusy) {
+		t.Errorf("second request to a busy host: got %v, want ErrHostBusy", err)
+	}
+	other, err := l.TryAcquire(ctx, "https://other.example/feed.xml")
+	if err != nil {
+		t.Errorf("other host: %v", err)
+	} else {
+		other()
+	}
+
+	release()
+	release, err = l.TryAcquire(ctx, "https://blog.example/feed.xml")
+	if err != nil {
+		t.Fatalf("after release: %v", err)
+	}
+	release()
+}
+
+func TestTryAcquireDeadline(t *testing.T) {
+	l := NewHostLimiter(time.Hour, 1, false)
+	release, err := l.TryAcquire(context.Background(), "https://blog.example/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// The host's next turn is an hour away, past the deadline, so the slot is
+	// given up at once without pushing the turn back further.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := l.TryAcquire(ctx, "https://blog.example/feed.xml"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want DeadlineExceeded", err)
+	}
+	if _, err := l.TryAcquire(ctx, "https://blog.example/feed.xml"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("slot not released: got %v, want DeadlineExceeded", err)
+	}
+}
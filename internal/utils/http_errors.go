@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPStatusError is returned by the fetcher for any response that is neither
+// 200 OK nor 304 Not Modified. RetryAfter is set when a 429 or 503 response
+// carries a usable Retry-After header.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("unexpected status %s (retry after %s)", e.Status, e.RetryAfter)
+	}
+	return fmt.Sprintf("unexpected status %s", e.Status)
+}
+
+func newHTTPStatusError(res *http.Response) *HTTPStatusError {
+	err := &HTTPStatusError{StatusCode: res.StatusCode, Status: res.Status}
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable {
+		err.RetryAfter = parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	}
+	return err
+}
+
+// parseRetryAfter understands both forms allowed by RFC 9110: a number of
+// seconds and an HTTP-date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package utils
+
+import "testing"
+
+func TestParseJSONFeedItem(t *testing.T) {
+	tests := []struct {
+		name string
+		item string
+		want FeedItem
+	}{
+		{
+			"string id",
+			`{"id": "a1", "title": "Hello", "url": "https://blog.example/1", "content_html": "<p>Hi</p>"}`,
+			FeedItem{ID: "a1", Title: "Hello", Link: "https://blog.example/1", Description: "<p>Hi</p>"},
+		},
+		{"numeric id", `{"id": 42}`, FeedItem{ID: "42"}},
+		{"large numeric id", `{"id": 12345678901234567890}`, FeedItem{ID: "12345678901234567890"}},
+		{
+			"url before external_url",
+			`{"id": "1", "url": "https://blog.example/1", "external_url": "https://other.example/"}`,
+			FeedItem{ID: "1", Link: "https://blog.example/1"},
+		},
+		{
+			"external_url without url",
+			`{"id": "1", "external_url": "https://other.example/"}`,
+			FeedItem{ID: "1", Link: "https://other.example/"},
+		},
+		{
+			"content_html first",
+			`{"id": "1", "content_html": "<p>html</p>", "content_text": "text", "summary": "summary"}`,
+			FeedItem{ID: "1", Description: "<p>html</p>"},
+		},
+		{
+			"content_text without content_html",
+			`{"id": "1", "content_text": "text", "summary": "summary"}`,
+			FeedItem{ID: "1", Description: "text"},
+		},
+		{"summary only", `{"id": "1", "summary": "summary"}`, FeedItem{ID: "1", Description: "summary"}},
+		{
+			"date_published",
+			`{"id": "1", "date_published": "2006-01-02T15:04:05Z", "date_modified": "2007-01-02T15:04:05Z"}`,
+			FeedItem{ID: "1", PubDate: "2006-01-02T15:04:05Z"},
+		},
+		{
+			"date_modified without date_published",
+			`{"id": "1", "date_modified": "2007-01-02T15:04:05Z"}`,
+			FeedItem{ID: "1", PubDate: "2007-01-02T15:04:05Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"version": "https://jsonfeed.org/version/1.1", "title": "Blog", "items": [` + tt.item + `]}`
+			feed, err := ParseFeed([]byte(body), "application/feed+json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(feed.Items) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Items))
+			}
+			if got := feed.Items[0]; got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		wantErr     bool
+	}{
+		{"version 1", `{"version": "https://jsonfeed.org/version/1", "title": "Blog"}`, "application/json", false},
+		{"version 1.1", `{"version": "https://jsonfeed.org/version/1.1", "title": "Blog"}`, "application/feed+json", false},
+		{"sniffed", `{"version": "https://jsonfeed.org/version/1.1", "title": "Blog"}`, "text/plain", false},
+		{"sniffed after BOM", "\xef\xbb\xbf\n  {\"version\": \"https://jsonfeed.org/version/1.1\", \"title\": \"Blog\"}", "", false},
+		{"BOM with JSON type", "\xef\xbb\xbf{\"version\": \"https://jsonfeed.org/version/1.1\", \"title\": \"Blog\"}", "application/json", false},
+		{"missing version", `{"title": "Blog"}`, "application/json", true},
+		{"other version", `{"version": "1.0", "title": "Blog"}`, "application/json", true},
+		{"other JSON document", `{"version": "https://example.com/version/1", "title": "Blog"}`, "application/json", true},
+		{"invalid id", `{"version": "https://jsonfeed.org/version/1", "items": [{"id": {"a": 1}}]}`, "application/json", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := ParseFeed([]byte(tt.body), tt.contentType)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", feed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if feed.Title != "Blog" {
+				t.Errorf("title = %q, want Blog", feed.Title)
+			}
+		})
+	}
+}
+
+func TestIsJSONFeed(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"JSON content type", "application/feed+json; charset=utf-8", "", true},
+		{"object", "", ` {"version": ""}`, true},
+		{"BOM then object", "", "\xef\xbb\xbf{}", true},
+		{"XML", "text/xml", `<?xml version="1.0"?><rss/>`, false},
+		{"BOM then XML", "", "\xef\xbb\xbf<rss/>", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isJSONFeed(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
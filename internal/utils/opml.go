@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// OPML is an OPML 1.0 or 2.0 subscription list.
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []OPMLOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// OPMLOutline is a single outline element. Attributes are kept raw because
+// readers disagree on the casing of xmlUrl and htmlUrl.
+type OPMLOutline struct {
+	Attrs    []xml.Attr    `xml:",any,attr"`
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// OPMLSubscription is a feed listed in an OPML file. Folder is the text of
+// the closest enclosing outline, empty for top-level feeds.
+type OPMLSubscription struct {
+	Title   string
+	XMLURL  string
+	HTMLURL string
+	Folder  string
+}
+
+func ParseOPML(r io.Reader) (*OPML, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var opml OPML
+	if err := unmarshalXML(data, &opml); err != nil {
+		return nil, fmt.Errorf("invalid OPML: %v", err)
+	}
+	return &opml, nil
+}
+
+// Attr looks an attribute up case-insensitively.
+func (o OPMLOutline) Attr(name string) string {
+	for _, attr := range o.Attrs {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return strings.TrimSpace(attr.Value)
+		}
+	}
+	return ""
+}
+
+// Subscriptions flattens the outline tree. Outlines with an xmlUrl or a type
+// are feeds, so a typed outline missing its xmlUrl is still reported; any
+// other outline is treated as a folder.
+func (o *OPML) Subscriptions() []OPMLSubscription {
+	var subs []OPMLSubscription
+	var walk func(outlines []OPMLOutline, folder string)
+	walk = func(outlines []OPMLOutline, folder string) {
+		for _, outline := range outlines {
+			title := outline.Attr("title")
+			if title == "" {
+				title = outline.Attr("text")
+			}
+			if xmlURL := outline.Attr("xmlUrl"); xmlURL != "" || outline.Attr("type") != "" {
+				subs = append(subs, OPMLSubscription{
+					Title:   title,
+					XMLURL:  xmlURL,
+					HTMLURL: outline.Attr("htmlUrl"),
+					Folder:  folder,
+				})
+				continue
+			}
+			walk(outline.Outlines, title)
+		}
+	}
+	walk(o.Body.Outlines, "")
+	return subs
+}
+
+// WriteOPML writes subs as an OPML 2.0 document, nesting feeds that have a
+// Folder under an outline of that name. Folders appear in order of first use.
+func WriteOPML(w io.Writer, title string, subs []OPMLSubscription) error {
+	opml := OPML{Version: "2.0"}
+	opml.Head.Title = title
+	opml.Head.DateCreated = time.Now().UTC().Format(time.RFC1123Z)
+
+	folders := make(map[string]int)
+	for _, sub := range subs {
+		attrs := []xml.Attr{
+			{Name: xml.Name{Local: "type"}, Value: "rss"},
+			{Name: xml.Name{Local: "text"}, Value: sub.Title},
+			{Name: xml.Name{Local: "title"}, Value: sub.Title},
+			{Name: xml.Name{Local: "xmlUrl"}, Value: sub.XMLURL},
+		}
+		if sub.HTMLURL != "" {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "htmlUrl"}, Value: sub.HTMLURL})
+		}
+		outline := OPMLOutline{Attrs: attrs}
+
+		if sub.Folder == "" {
+			opml.Body.Outlines = append(opml.Body.Outlines, outline)
+			continue
+		}
+		i, ok := folders[sub.Folder]
+		if !ok {
+			i = len(opml.Body.Outlines)
+			folders[sub.Folder] = i
+			opml.Body.Outlines = append(opml.Body.Outlines, OPMLOutline{Attrs: []xml.Attr{
+				{Name: xml.Name{Local: "text"}, Value: sub.Folder},
+				{Name: xml.Name{Local: "title"}, Value: sub.Folder},
+			}})
+		}
+		opml.Body.Outlines[i].Outlines = append(opml.Body.Outlines[i].Outlines, outline)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(opml); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
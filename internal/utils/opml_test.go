@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubscriptions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []OPMLSubscription
+	}{
+		{
+			"top level",
+			`<outline type="rss" text="Blog" xmlUrl="https://blog.example/feed.xml" htmlUrl="https://blog.example/"/>`,
+			[]OPMLSubscription{{Title: "Blog", XMLURL: "https://blog.example/feed.xml", HTMLURL: "https://blog.example/"}},
+		},
+		{
+			"title over text",
+			`<outline text="Text" title="Title" xmlUrl="https://blog.example/feed.xml"/>`,
+			[]OPMLSubscription{{Title: "Title", XMLURL: "https://blog.example/feed.xml"}},
+		},
+		{
+			"attribute casing",
+			`<outline text="A" xmlURL="https://a.example/feed.xml" htmlURL="https://a.example/"/>
+			<outline text="B" XMLURL="https://b.example/feed.xml"/>`,
+			[]OPMLSubscription{
+				{Title: "A", XMLURL: "https://a.example/feed.xml", HTMLURL: "https://a.example/"},
+				{Title: "B", XMLURL: "https://b.example/feed.xml"},
+			},
+		},
+		{
+			"folder",
+			`<outline text="Go"><outline text="Blog" xmlUrl="https://blog.example/feed.xml"/></outline>
+			<outline text="News" xmlUrl="https://news.example/rss"/>`,
+			[]OPMLSubscription{
+				{Title: "Blog", XMLURL: "https://blog.example/feed.xml", Folder: "Go"},
+				{Title: "News", XMLURL: "https://news.example/rss"},
+			},
+		},
+		{
+			"nested folders use the closest",
+			`<outline title="Tech"><outline text="Go">
+				<outline text="Blog" xmlUrl="https://blog.example/feed.xml"/>
+			</outline><outline text="Hacker News" xmlUrl="https://news.example/rss"/></outline>`,
+			[]OPMLSubscription{
+				{Title: "Blog", XMLURL: "https://blog.example/feed.xml", Folder: "Go"},
+				{Title: "Hacker News", XMLURL: "https://news.example/rss", Folder: "Tech"},
+			},
+		},
+		{
+			"typed outline without xmlUrl",
+			`<outline type="rss" text="Broken"/><outline text="Empty folder"/>`,
+			[]OPMLSubscription{{Title: "Broken"}},
+		},
+		{
+			"typed outline is not a folder",
+			`<outline type="rss" text="Odd" xmlUrl="https://odd.example/rss">
+				<outline text="Child" xmlUrl="https://child.example/rss"/>
+			</outline>`,
+			[]OPMLSubscription{{Title: "Odd", XMLURL: "https://odd.example/rss"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opml, err := ParseOPML(strings.NewReader(`<?xml version="1.0"?>
+<opml version="2.0"><head><title>Subscriptions</title></head><body>` + tt.body + `</body></opml>`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := opml.Subscriptions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOPMLInvalid(t *testing.T) {
+	if _, err := ParseOPML(strings.NewReader("<opml><body><outline></body></opml>")); err == nil {
+		t.Error("want an error for malformed OPML")
+	}
+}
+
+func TestWriteOPMLRoundTrip(t *testing.T) {
+	subs := []OPMLSubscription{
+		{Title: "Blog", XMLURL: "https://blog.example/feed.xml", HTMLURL: "https://blog.example/"},
+		{Title: "The Go Blog", XMLURL: "https://go.example/feed.atom", Folder: "Go"},
+		{Title: "News & <views>", XMLURL: "https://news.example/rss?a=1&b=2", Folder: "Tech"},
+		{Title: "Another Go feed", XMLURL: "https://go2.example/rss", Folder: "Go"},
+	}
+	var buf strings.Builder
+	if err := WriteOPML(&buf, "alice's subscriptions", subs); err != nil {
+		t.Fatal(err)
+	}
+	opml, err := ParseOPML(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opml.Version != "2.0" || opml.Head.Title != "alice's subscriptions" || opml.Head.DateCreated == "" {
+		t.Errorf("head = %q %+v", opml.Version, opml.Head)
+	}
+
+	// Feeds in a folder are grouped under it, where it is first used.
+	want := []OPMLSubscription{subs[0], subs[1], subs[3], subs[2]}
+	if got := opml.Subscriptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
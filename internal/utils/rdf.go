@@ -0,0 +1,47 @@
+package utils
+
+import "strings"
+
+// rdfFeed is an RSS 1.0 document. Unlike RSS 2.0, its items are siblings of
+// the channel rather than children of it.
+type rdfFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+	} `xml:"channel"`
+	Items []rdfItem `xml:"item"`
+}
+
+type rdfItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+func parseRDF(body []byte) (*ParsedFeed, error) {
+	var resFeed rdfFeed
+	if err := unmarshalXML(body, &resFeed); err != nil {
+		return nil, err
+	}
+
+	feed := &ParsedFeed{
+		Title:       strings.TrimSpace(resFeed.Channel.Title),
+		Link:        strings.TrimSpace(resFeed.Channel.Link),
+		Description: strings.TrimSpace(resFeed.Channel.Description),
+	}
+	for _, item := range resFeed.Items {
+		feed.Items = append(feed.Items, FeedItem{
+			ID:          item.About,
+			Title:       strings.TrimSpace(item.Title),
+			Link:        strings.TrimSpace(item.Link),
+			Description: strings.TrimSpace(item.Description),
+			PubDate:     strings.TrimSpace(item.Date),
+			Author:      strings.TrimSpace(item.Creator),
+		})
+	}
+	return feed, nil
+}
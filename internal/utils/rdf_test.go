@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRDF(t *testing.T) {
+	feed, err := ParseFeed([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+  xmlns="http://purl.org/rss/1.0/"
+  xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://journal.example/">
+    <title> Journal </title>
+    <link>https://journal.example/</link>
+    <description>Papers</description>
+    <items>
+      <rdf:Seq>
+        <rdf:li rdf:resource="https://journal.example/1"/>
+        <rdf:li rdf:resource="https://journal.example/2"/>
+      </rdf:Seq>
+    </items>
+  </channel>
+  <item rdf:about="https://journal.example/1">
+    <title>First &amp;amp; best</title>
+    <link> https://journal.example/1 </link>
+    <description>Abstract</description>
+    <dc:date>2006-01-02T15:04:05Z</dc:date>
+    <dc:creator> Ada Lovelace </dc:creator>
+  </item>
+  <item rdf:about="https://journal.example/2">
+    <title>Second</title>
+    <link>https://journal.example/2</link>
+    <date>2007-01-02T15:04:05Z</date>
+    <creator>Not Dublin Core</creator>
+  </item>
+</rdf:RDF>`), "application/rdf+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Journal" || feed.Link != "https://journal.example/" || feed.Description != "Papers" {
+		t.Errorf("got title %q, link %q, description %q", feed.Title, feed.Link, feed.Description)
+	}
+	want := []FeedItem{
+		{
+			ID:          "https://journal.example/1",
+			Title:       "First & best",
+			Link:        "https://journal.example/1",
+			Description: "Abstract",
+			PubDate:     "2006-01-02T15:04:05Z",
+			Author:      "Ada Lovelace",
+		},
+		// Only the Dublin Core namespace counts for date and creator.
+		{
+			ID:    "https://journal.example/2",
+			Title: "Second",
+			Link:  "https://journal.example/2",
+		},
+	}
+	if !reflect.DeepEqual(feed.Items, want) {
+		t.Errorf("got %+v, want %+v", feed.Items, want)
+	}
+}
@@ -1,55 +1,292 @@
 package utils
 
 import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"encoding/xml"
+	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"strings"
 )
 
+// ParsedFeed is the format-independent view of a fetched feed. Every
+// supported format is normalised into it before reaching the handlers.
+type ParsedFeed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []FeedItem
+}
+
+type FeedItem struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	PubDate     string
+	Author      string
+}
+
 type RSSFeed struct {
 	Channel struct {
 		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
+		Link        rssLinks  `xml:"link"`
 		Description string    `xml:"description"`
 		Item        []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        rssLinks `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	GUID        string   `xml:"guid"`
+}
+
+// rssLinks collects every <link> child, because feeds routinely add an
+// <atom:link rel="self"/> next to the plain RSS one and a single string field
+// would end up holding whichever came last.
+type rssLinks []struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// String returns the text of the first un-namespaced <link>.
+func (l rssLinks) String() string {
+	for _, link := range l {
+		if link.XMLName.Space == "" && strings.TrimSpace(link.Value) != "" {
+			return strings.TrimSpace(link.Value)
+		}
+	}
+	return ""
+}
+
+// DefaultMaxBodyBytes caps a feed response when FetchOptions.MaxBytes is
+// unset. It applies to the decompressed body as well as the bytes on the wire.
+const DefaultMaxBodyBytes = 10 << 20
+
+// FetchOptions tunes a single fetch. ETag and LastModified are the
+// validators from the previous fetch, if any.
+type FetchOptions struct {
+	ETag         string
+	LastModified string
+	MaxBytes     int64
+}
+
+// FetchResult is the outcome of a conditional fetch. When the server answers
+// 304 Not Modified, NotModified is set and Feed is nil. ETag and LastModified
+// carry the validators to send on the next request.
+type FetchResult struct {
+	Feed         *ParsedFeed
+	NotModified  bool
+	ETag         string
+	LastModified string
 }
 
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// FetchFeedConditional fetches a feed, sending If-None-Match and
+// If-Modified-Since when validators from a previous fetch are given.
+func FetchFeedConditional(ctx context.Context, feedURL string, opts FetchOptions) (*FetchResult, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("User-Agent", userAgent)
+	// Setting Accept-Encoding ourselves turns off the transport's transparent
+	// gzip handling, so readBody decodes the response itself.
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	if opts.ETag != "" {
+		req.Header.Set("If-None-Match", opts.ETag)
+	}
+	if opts.LastModified != "" {
+		req.Header.Set("If-Modified-Since", opts.LastModified)
+	}
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
+	result := &FetchResult{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+	if res.StatusCode == http.StatusNotModified {
+		// A 304 may omit validators that are still current.
+		result.NotModified = true
+		if result.ETag == "" {
+			result.ETag = opts.ETag
+		}
+		if result.LastModified == "" {
+			result.LastModified = opts.LastModified
+		}
+		return result, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, newHTTPStatusError(res)
+	}
+
+	body, err := readBody(res, opts.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	result.Feed, err = ParseFeed(body, res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// readBody reads a response body of at most maxBytes, decoding gzip and
+// deflate content encodings.
+func readBody(res *http.Response, maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+
+	var body io.Reader = &cappedReader{r: res.Body, remaining: maxBytes, limit: maxBytes}
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %v", err)
+		}
+		defer gz.Close()
+		body = gz
+	case "deflate":
+		// "deflate" is meant to be zlib-wrapped, but some servers send raw
+		// DEFLATE data instead.
+		buffered := bufio.NewReader(body)
+		header, err := buffered.Peek(2)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deflate body: %v", err)
+		}
+		if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zr, err := zlib.NewReader(buffered)
+			if err != nil {
+				return nil, fmt.Errorf("invalid deflate body: %v", err)
+			}
+			defer zr.Close()
+			body = zr
+		} else {
+			fr := flate.NewReader(buffered)
+			defer fr.Close()
+			body = fr
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", res.Header.Get("Content-Encoding"))
+	}
+
+	return io.ReadAll(&cappedReader{r: body, remaining: maxBytes, limit: maxBytes})
+}
+
+// cappedReader fails with an error, rather than silently truncating, once
+// more than limit bytes have been read.
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		// Probe for one more byte to tell a body of exactly limit bytes
+		// from a larger one.
+		var probe [1]byte
+		n, err := c.r.Read(probe[:])
+		if n > 0 {
+			return 0, fmt.Errorf("feed exceeds the %d byte size limit", c.limit)
+		}
+		return 0, err
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	return n, err
+}
+
+// ParseFeed decodes a feed document into a ParsedFeed. JSON Feed is chosen by
+// content type or by sniffing the body; XML formats are told apart by their
+// root element.
+func ParseFeed(body []byte, contentType string) (*ParsedFeed, error) {
+	feed, err := parseFeedBody(body, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	feed.Title = html.UnescapeString(feed.Title)
+	feed.Description = html.UnescapeString(feed.Description)
+	for i := range feed.Items {
+		feed.Items[i].Title = html.UnescapeString(feed.Items[i].Title)
+		feed.Items[i].Description = html.UnescapeString(feed.Items[i].Description)
+	}
+	return feed, nil
+}
+
+func parseFeedBody(body []byte, contentType string) (*ParsedFeed, error) {
+	if isJSONFeed(contentType, body) {
+		return parseJSONFeed(body)
+	}
+
+	root, err := rootElement(body)
 	if err != nil {
 		return nil, err
 	}
+	switch root {
+	case "rss":
+		return parseRSS(body)
+	case "feed":
+		return parseAtom(body)
+	case "RDF":
+		return parseRDF(body)
+	default:
+		return nil, fmt.Errorf("unsupported feed format: root element <%s>", root)
+	}
+}
+
+func rootElement(body []byte) (string, error) {
+	decoder := newXMLDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", fmt.Errorf("document has no root element")
+		}
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseRSS(body []byte) (*ParsedFeed, error) {
 	var resFeed RSSFeed
-	if err := xml.Unmarshal(body, &resFeed); err != nil {
+	if err := unmarshalXML(body, &resFeed); err != nil {
 		return nil, err
 	}
-	resFeed.Channel.Title = html.UnescapeString(resFeed.Channel.Title)
-	resFeed.Channel.Description = html.UnescapeString(resFeed.Channel.Description)
 
-	for i := range resFeed.Channel.Item {
-		resFeed.Channel.Item[i].Title = html.UnescapeString(resFeed.Channel.Item[i].Title)
-		resFeed.Channel.Item[i].Description = html.UnescapeString(resFeed.Channel.Item[i].Description)
+	feed := &ParsedFeed{
+		Title:       resFeed.Channel.Title,
+		Link:        resFeed.Channel.Link.String(),
+		Description: resFeed.Channel.Description,
+	}
+	for _, item := range resFeed.Channel.Item {
+		feed.Items = append(feed.Items, FeedItem{
+			ID:          item.GUID,
+			Title:       item.Title,
+			Link:        item.Link.String(),
+			Description: item.Description,
+			PubDate:     item.PubDate,
+		})
 	}
-	return &resFeed, nil
+	return feed, nil
 }
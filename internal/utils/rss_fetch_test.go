@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func gzipped(data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func zlibbed(data string) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func deflated(data string) []byte {
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestReadBody(t *testing.T) {
+	feed := "<rss>" + strings.Repeat("x", 95) + "</rss>" // 106 bytes
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		maxBytes int64
+		wantErr  string
+	}{
+		{"identity", "", []byte(feed), 0, ""},
+		{"explicit identity", "identity", []byte(feed), 0, ""},
+		{"gzip", "gzip", gzipped(feed), 0, ""},
+		{"x-gzip", "X-Gzip", gzipped(feed), 0, ""},
+		{"zlib deflate", "deflate", zlibbed(feed), 0, ""},
+		{"raw deflate", "deflate", deflated(feed), 0, ""},
+		{"exactly the limit", "", []byte(feed), int64(len(feed)), ""},
+		{"one byte over", "", []byte(feed), int64(len(feed)) - 1, "size limit"},
+		{"gzip exactly the limit", "gzip", gzipped(feed), int64(len(feed)), ""},
+		{"gzip one byte over", "gzip", gzipped(feed), int64(len(feed)) - 1, "size limit"},
+		{"deflate one byte over", "deflate", zlibbed(feed), int64(len(feed)) - 1, "size limit"},
+		{"invalid gzip", "gzip", []byte(feed), 0, "invalid gzip body"},
+		{"empty deflate", "deflate", nil, 0, "invalid deflate body"},
+		{"unsupported", "br", []byte(feed), 0, "unsupported content encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{
+				Header: http.Header{},
+				Body:   io.NopCloser(bytes.NewReader(tt.body)),
+			}
+			if tt.encoding != "" {
+				res.Header.Set("Content-Encoding", tt.encoding)
+			}
+			got, err := readBody(res, tt.maxBytes)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != feed {
+				t.Errorf("got %q, want %q", got, feed)
+			}
+		})
+	}
+}
+
+// A small compressed body can expand far past the limit, so the limit
+// applies to the decompressed bytes too.
+func TestReadBodyCompressionBomb(t *testing.T) {
+	body := gzipped(strings.Repeat("x", 1<<20))
+	res := &http.Response{
+		Header: http.Header{"Content-Encoding": {"gzip"}},
+		Body:   io.NopCloser(bytes.NewReader(body)),
+	}
+	if _, err := readBody(res, int64(len(body))*2); err == nil || !strings.Contains(err.Error(), "size limit") {
+		t.Errorf("got %v, want the size limit error", err)
+	}
+}
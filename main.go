@@ -1,15 +1,12 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
 	"gator/internal/app"
 	"gator/internal/config"
-	"gator/internal/database"
 	"gator/internal/handlers"
+	"gator/internal/storage"
 	"os"
-
-	_ "github.com/lib/pq"
 )
 
 func main() {
@@ -21,21 +18,24 @@ func main() {
 	}
 
 	// Initialize database
-	db, err := sql.Open("postgres", configFile.DbUrl)
-	dbQueries := database.New(db)
+	store, migrator, err := storage.Open(configFile.DbUrl)
 	if err != nil {
 		fmt.Printf("Failed to initialize database: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Initialize AppState and Commands
-	appState := app.NewAppState(&configFile, dbQueries)
+	appState := app.NewAppState(&configFile, store, migrator)
 	commands := app.NewCommands()
 
 	// Register commands
 	handlers.RegisterUserHandlers(commands)
 	handlers.RegisterRSSHandlers(commands)
 	handlers.RegisterFeedFollowHandlers(commands)
+	handlers.RegisterFolderHandlers(commands)
+	handlers.RegisterPostHandlers(commands)
+	handlers.RegisterOPMLHandlers(commands)
+	handlers.RegisterMigrateHandlers(commands)
 
 	// Parse and execute command-line arguments
 	args := os.Args[1:]
@@ -45,6 +45,12 @@ func main() {
 	}
 
 	command := app.Command{Name: args[0], Args: args[1:]}
+	if command.Name != "migrate" {
+		if err := handlers.CheckSchema(&appState); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	if err := commands.Run(&appState, command); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
@@ -1,7 +1,7 @@
 -- name: CreateFeedFollow :one
 WITH new_follow AS (
-    INSERT INTO feed_follow (id, user_id, feed_id)
-    VALUES ($1, $2, $3)
+    INSERT INTO feed_follow (id, user_id, feed_id, folder_id)
+    VALUES ($1, $2, $3, $4)
     RETURNING *
 )
 SELECT 
@@ -16,4 +16,25 @@ JOIN feeds ON feeds.id = new_follow.feed_id;
 SELECT * FROM feed_follow WHERE user_id = $1;
 
 -- name: DeleteFeedFollow :exec
-DELETE FROM feed_follow WHERE user_id = $1 AND feed_id = $2;
\ No newline at end of file
+DELETE FROM feed_follow WHERE user_id = $1 AND feed_id = $2;
+
+-- name: SetFeedFollowTitle :execrows
+UPDATE feed_follow SET title = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: EnsureFeedFollow :execrows
+INSERT INTO feed_follow (id, user_id, feed_id, folder_id, title)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, feed_id) DO NOTHING;
+
+-- name: GetFollowedFeeds :many
+-- Each feed comes with the title the user gave it, or its own name.
+SELECT feeds.*, coalesce(feed_follow.title, feeds.name) AS display_name, folders.name AS folder_name FROM feeds
+JOIN feed_follow ON feed_follow.feed_id = feeds.id
+LEFT JOIN folders ON folders.id = feed_follow.folder_id
+WHERE feed_follow.user_id = $1
+ORDER BY display_name;
+
+-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follow SET folder_id = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2;
@@ -1,12 +1,14 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, site_url, description)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8
 )
 RETURNING *;
 
@@ -14,10 +16,68 @@ RETURNING *;
 SELECT * FROM feeds WHERE id = $1;
 
 -- name: GetFeeds :many
-SELECT name, url, user_id FROM feeds;
+SELECT * FROM feeds;
 
 -- name: GetFeedByURL :one
 SELECT * FROM feeds WHERE url = $1;
 
 -- name: GetFeedNameById :one
-SELECT name FROM feeds WHERE id = $1;
\ No newline at end of file
+SELECT name FROM feeds WHERE id = $1;
+
+-- name: ClaimFeedsToFetch :many
+-- Leases the stalest due feeds to one scraper instance. SKIP LOCKED lets
+-- concurrent instances claim disjoint sets; an expired lease is up for grabs
+-- again, so feeds held by a crashed instance are picked up by the others.
+-- last_fetched_at is left to MarkFeedFetched, so a feed handed back unfetched
+-- keeps its place in the queue.
+UPDATE feeds
+SET claimed_by = @claimed_by::text,
+    lease_expires_at = NOW() + make_interval(secs => @lease_seconds::float8),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL
+    AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    AND (lease_expires_at IS NULL OR lease_expires_at <= NOW())
+    ORDER BY last_fetched_at ASC NULLS FIRST
+    LIMIT @max_feeds
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkFeedFetched :exec
+UPDATE feeds SET last_fetched_at = NOW(), updated_at = NOW() WHERE id = $1;
+
+-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET claimed_by = NULL,
+    lease_expires_at = NULL
+WHERE id = @id AND claimed_by = @claimed_by::text;
+
+-- name: SetFeedCacheValidators :exec
+UPDATE feeds SET etag = $2, last_modified = $3, updated_at = NOW() WHERE id = $1;
+
+-- name: RecordFeedFetchFailure :exec
+-- Both timestamps come from the database clock, the one ClaimFeedsToFetch
+-- compares them against.
+UPDATE feeds
+SET last_error = @last_error,
+    consecutive_failures = consecutive_failures + 1,
+    next_fetch_at = NOW() + make_interval(secs => @backoff_seconds::float8),
+    disabled_at = CASE WHEN @disable::boolean THEN NOW() END,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: ResetFeedFetchErrors :exec
+UPDATE feeds
+SET last_error = NULL,
+    consecutive_failures = 0,
+    next_fetch_at = NULL,
+    disabled_at = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetBrokenFeeds :many
+SELECT * FROM feeds
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY consecutive_failures DESC, name;
@@ -0,0 +1,29 @@
+-- name: CreateFolder :one
+INSERT INTO folders (id, created_at, updated_at, user_id, name)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: GetFolderByName :one
+SELECT * FROM folders WHERE user_id = $1 AND name = $2;
+
+-- name: GetFoldersForUser :many
+SELECT folders.*, COUNT(feed_follow.id) AS feed_count
+FROM folders
+LEFT JOIN feed_follow ON feed_follow.folder_id = folders.id
+WHERE folders.user_id = $1
+GROUP BY folders.id
+ORDER BY folders.name;
+
+-- name: RenameFolder :execrows
+UPDATE folders SET name = @new_name, updated_at = NOW()
+WHERE user_id = @user_id AND name = @name;
+
+-- name: DeleteFolder :execrows
+-- Feeds filed in the folder stay followed, without a folder.
+DELETE FROM folders WHERE user_id = $1 AND name = $2;
@@ -0,0 +1,20 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostUnread :execrows
+DELETE FROM post_reads WHERE user_id = $1 AND post_id = $2;
+
+-- name: MarkAllPostsRead :execrows
+-- Marks every unread post in the user's feeds as read, optionally only those
+-- of one feed or published before a cutoff.
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follow.user_id, posts.id, @read_at::timestamp
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follow ON feed_follow.feed_id = posts.feed_id
+WHERE feed_follow.user_id = @user_id
+AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url')::text)
+AND (sqlc.narg('before')::timestamp IS NULL OR posts.published_at < sqlc.narg('before')::timestamp)
+ON CONFLICT (user_id, post_id) DO NOTHING;
@@ -0,0 +1,21 @@
+-- name: StarPost :exec
+INSERT INTO post_stars (user_id, post_id, starred_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnstarPost :execrows
+-- Takes a post ID or link, and works whether or not the user still follows
+-- the post's feed.
+DELETE FROM post_stars
+WHERE user_id = @user_id
+AND post_id IN (SELECT id FROM posts WHERE posts.id::text = @post OR posts.url = @post);
+
+-- name: GetStarredPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_unknown, posts.author,
+    coalesce(feed_follow.title, feeds.name) AS feed_name, post_stars.starred_at
+FROM post_stars
+JOIN posts ON posts.id = post_stars.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN feed_follow ON feed_follow.feed_id = posts.feed_id AND feed_follow.user_id = post_stars.user_id
+WHERE post_stars.user_id = $1
+ORDER BY post_stars.starred_at DESC;
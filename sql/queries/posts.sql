@@ -0,0 +1,68 @@
+-- name: CreatePost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, published_at_unknown, feed_id, author)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
+)
+ON CONFLICT (feed_id, url) DO UPDATE SET
+    updated_at = EXCLUDED.updated_at,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    author = EXCLUDED.author,
+    -- An unknown date falls back to fetch time; keep the first one seen so
+    -- undated posts don't jump to the top on every fetch.
+    published_at = CASE WHEN EXCLUDED.published_at_unknown THEN posts.published_at ELSE EXCLUDED.published_at END,
+    published_at_unknown = posts.published_at_unknown AND EXCLUDED.published_at_unknown
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_unknown, author;
+
+-- name: GetPostsForUser :many
+-- Lists the newest posts in the user's feeds, only unread ones unless
+-- include_read is set and only those filed in folder_id when it is given.
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_unknown, posts.author,
+    coalesce(feed_follow.title, feeds.name) AS feed_name, post_reads.read_at
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follow ON feed_follow.feed_id = posts.feed_id
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follow.user_id
+WHERE feed_follow.user_id = @user_id
+AND (@include_read::boolean OR post_reads.read_at IS NULL)
+AND (sqlc.narg('folder_id')::uuid IS NULL OR feed_follow.folder_id = sqlc.narg('folder_id')::uuid)
+ORDER BY posts.published_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetPostForUser :one
+-- Finds a post in one of the user's feeds by its ID or its link.
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_unknown, posts.author
+FROM posts
+JOIN feed_follow ON feed_follow.feed_id = posts.feed_id
+WHERE feed_follow.user_id = @user_id
+AND (posts.id::text = @post OR posts.url = @post)
+ORDER BY posts.published_at DESC
+LIMIT 1;
+
+-- name: SearchPosts :many
+-- Ranks posts in the user's feeds against a web-search style query, with the
+-- matching words highlighted in the snippet.
+SELECT posts.id, posts.title, posts.url, posts.published_at, posts.published_at_unknown,
+    coalesce(feed_follow.title, feeds.name) AS feed_name,
+    ts_rank(posts.search_vector, query)::real AS rank,
+    ts_headline('english', coalesce(posts.description, posts.title), query,
+        'StartSel=**, StopSel=**, MaxWords=30, MinWords=10, MaxFragments=2')::text AS snippet
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follow ON feed_follow.feed_id = posts.feed_id,
+    websearch_to_tsquery('english', @query::text) AS query
+WHERE feed_follow.user_id = @user_id
+AND posts.search_vector @@ query
+AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url')::text)
+AND (sqlc.narg('since')::timestamp IS NULL OR posts.published_at >= sqlc.narg('since')::timestamp)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg('limit');
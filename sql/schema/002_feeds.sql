@@ -1,3 +1,4 @@
+-- +goose Up
 CREATE TABLE
     feeds (
         id UUID PRIMARY KEY NOT NULL,
@@ -9,3 +10,5 @@ CREATE TABLE
         FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
     );
 
+-- +goose Down
+DROP TABLE feeds;
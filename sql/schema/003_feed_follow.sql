@@ -1,3 +1,4 @@
+-- +goose Up
 CREATE TABLE
     feed_follow (
         id UUID PRIMARY KEY,
@@ -8,4 +9,7 @@ CREATE TABLE
         feed_id UUID,
         FOREIGN KEY (feed_id) REFERENCES feeds (id) ON DELETE CASCADE,
         UNIQUE (user_id, feed_id)
-    );
\ No newline at end of file
+    );
+
+-- +goose Down
+DROP TABLE feed_follow;
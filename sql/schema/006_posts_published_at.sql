@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN published_at_unknown BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE posts SET published_at = created_at, published_at_unknown = TRUE WHERE published_at IS NULL;
+
+ALTER TABLE posts ALTER COLUMN published_at SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE posts ALTER COLUMN published_at DROP NOT NULL;
+
+UPDATE posts SET published_at = NULL WHERE published_at_unknown;
+
+ALTER TABLE posts DROP COLUMN published_at_unknown;
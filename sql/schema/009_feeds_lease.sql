@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN claimed_by TEXT;
+
+ALTER TABLE feeds ADD COLUMN lease_expires_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN lease_expires_at;
+
+ALTER TABLE feeds DROP COLUMN claimed_by;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- There is no retention cleanup of old posts yet; when one is added it must
+-- keep every post referenced here.
+CREATE TABLE
+    post_stars (
+        user_id UUID NOT NULL,
+        post_id UUID NOT NULL,
+        starred_at TIMESTAMP NOT NULL,
+        PRIMARY KEY (user_id, post_id),
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+        FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+    );
+
+-- +goose Down
+DROP TABLE post_stars;
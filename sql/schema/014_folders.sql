@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE
+    folders (
+        id UUID PRIMARY KEY,
+        created_at TIMESTAMP NOT NULL,
+        updated_at TIMESTAMP NOT NULL,
+        user_id UUID NOT NULL,
+        name TEXT NOT NULL,
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+        UNIQUE (user_id, name)
+    );
+
+ALTER TABLE feed_follow ADD COLUMN folder_id UUID REFERENCES folders (id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE feed_follow DROP COLUMN folder_id;
+
+DROP TABLE folders;
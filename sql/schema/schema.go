@@ -0,0 +1,21 @@
+// Package schema embeds the goose-style migrations in this directory so gator
+// can apply them itself. The sqlite directory holds the same migrations,
+// version for version, written for SQLite.
+package schema
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
+
+// SQLite returns the SQLite migrations.
+func SQLite() fs.FS {
+	sub, err := fs.Sub(FS, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE
+    users (
+        id TEXT PRIMARY KEY,
+        created_at TIMESTAMP,
+        updated_at TIMESTAMP,
+        name TEXT
+    );
+
+-- +goose Down
+DROP TABLE users;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE
+    feeds (
+        id TEXT PRIMARY KEY NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        updated_at TIMESTAMP NOT NULL,
+        name TEXT NOT NULL,
+        url TEXT UNIQUE NOT NULL,
+        user_id TEXT NOT NULL,
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    );
+
+-- +goose Down
+DROP TABLE feeds;
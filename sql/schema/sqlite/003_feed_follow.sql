@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE
+    feed_follow (
+        id TEXT PRIMARY KEY,
+        created_at TIMESTAMP NOT NULL DEFAULT (strftime ('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+        updated_at TIMESTAMP NOT NULL DEFAULT (strftime ('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+        user_id TEXT,
+        feed_id TEXT,
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+        FOREIGN KEY (feed_id) REFERENCES feeds (id) ON DELETE CASCADE,
+        UNIQUE (user_id, feed_id)
+    );
+
+-- +goose Down
+DROP TABLE feed_follow;
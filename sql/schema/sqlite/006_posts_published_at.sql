@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN published_at_unknown BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- SQLite cannot add NOT NULL to an existing column; CreatePost always sets
+-- published_at, so the backfill is all that is needed.
+UPDATE posts SET published_at = created_at, published_at_unknown = TRUE WHERE published_at IS NULL;
+
+-- +goose Down
+UPDATE posts SET published_at = NULL WHERE published_at_unknown;
+
+ALTER TABLE posts DROP COLUMN published_at_unknown;
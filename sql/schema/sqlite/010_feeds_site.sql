@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN site_url TEXT;
+
+ALTER TABLE feeds ADD COLUMN description TEXT;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN description;
+
+ALTER TABLE feeds DROP COLUMN site_url;
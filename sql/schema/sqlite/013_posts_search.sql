@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQLite has no tsvector. The column only exists so posts has the same shape
+-- on both backends; search ranks posts with search_rank instead.
+ALTER TABLE posts ADD COLUMN search_vector TEXT GENERATED ALWAYS AS (
+    title || ' ' || coalesce(description, '')
+) VIRTUAL;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN search_vector;
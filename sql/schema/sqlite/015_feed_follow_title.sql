@@ -0,0 +1,6 @@
+-- +goose Up
+-- A follower's own name for the feed, shown instead of feeds.name when set.
+ALTER TABLE feed_follow ADD COLUMN title TEXT;
+
+-- +goose Down
+ALTER TABLE feed_follow DROP COLUMN title;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- The item's author, such as RSS 1.0's dc:creator, when the feed names one.
+ALTER TABLE posts ADD COLUMN author TEXT;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN author;